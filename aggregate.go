@@ -0,0 +1,351 @@
+package iso8601duration
+
+import (
+	"math"
+	"time"
+)
+
+// Min returns the shortest of the durations according to Compare. Nil
+// entries are skipped and ties go to the first one. Min returns nil if
+// there is no duration to compare.
+func Min(ds ...*Duration) *Duration {
+	return extreme(ds, -1, (*Duration).Compare)
+}
+
+// Max returns the longest of the durations according to Compare. Nil
+// entries are skipped and ties go to the first one. Max returns nil if
+// there is no duration to compare.
+func Max(ds ...*Duration) *Duration {
+	return extreme(ds, 1, (*Duration).Compare)
+}
+
+// MinOf works like Min, but compares the durations with CompareAt
+// starting at at, so P1M is shorter than P30D in February
+func MinOf(at time.Time, ds ...*Duration) *Duration {
+	return extreme(ds, -1, func(a, b *Duration) int { return a.CompareAt(at, b) })
+}
+
+// MaxOf works like Max, but compares the durations with CompareAt
+// starting at at
+func MaxOf(at time.Time, ds ...*Duration) *Duration {
+	return extreme(ds, 1, func(a, b *Duration) int { return a.CompareAt(at, b) })
+}
+
+// extreme returns the first duration which compares as want to all
+// others
+func extreme(ds []*Duration, want int, compare func(a, b *Duration) int) *Duration {
+	var res *Duration
+	for _, d := range ds {
+		if d != nil && (res == nil || compare(d, res) == want) {
+			res = d
+		}
+	}
+	return res
+}
+
+// Clamp returns min if the duration is shorter than min and max if it
+// is longer than max, comparing them with CompareAt starting at at.
+// Otherwise the duration itself is returned. A nil min or max leaves
+// that side unbounded. If min is longer than max, they are swapped, so
+// the result always lies between both bounds.
+func (d *Duration) Clamp(min, max *Duration, at time.Time) *Duration {
+	if min != nil && max != nil && min.CompareAt(at, max) > 0 {
+		min, max = max, min
+	}
+	if min != nil && d.CompareAt(at, min) < 0 {
+		return min
+	}
+	if max != nil && d.CompareAt(at, max) > 0 {
+		return max
+	}
+	return d
+}
+
+// Compare returns -1, 0 or +1 depending on whether the duration is
+// shorter than, as long as or longer than other. The comparison is
+// based on ToEstimatedDuration, so P1M and P30D compare as equal even
+// though they differ in most months. Estimates beyond the range of
+// time.Duration, e.g. for P300Y, do not wrap around. Use
+// Estimator.Compare for other lengths of years and months, and
+// CompareAt to compare exactly.
+func (d *Duration) Compare(other *Duration) int {
+	return Estimator{}.Compare(d, other)
+}
+
+// CompareAt works like Compare, but compares the exact durations when
+// starting at from, as returned by ToDuration. P1M is shorter than P30D
+// in February, but longer in July.
+func (d *Duration) CompareAt(from time.Time, other *Duration) int {
+	a, b := d.ToDuration(from), other.ToDuration(from)
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Less reports whether the duration is shorter than other according to
+// Compare
+func (d *Duration) Less(other *Duration) bool {
+	return d.Compare(other) < 0
+}
+
+// Durations attaches the methods of sort.Interface to a slice of
+// durations, sorting them in increasing order according to Compare,
+// e.g. with sort.Sort(Durations(ds)).
+type Durations []*Duration
+
+func (s Durations) Len() int           { return len(s) }
+func (s Durations) Less(i, j int) bool { return s[i].Less(s[j]) }
+func (s Durations) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Sum adds the durations like repeated calls to Add, e.g. P3DT1H for
+// P1D, P2D and PT1H. Nil entries are skipped, so Sum returns the zero
+// duration if there is nothing to add. It panics like Add if a
+// component overflows.
+func Sum(ds ...*Duration) *Duration {
+	res := &Duration{}
+	for _, d := range ds {
+		res = res.Add(d)
+	}
+	return res
+}
+
+// Add returns the sum of both durations, component by component, e.g.
+// P3M15D for P1M and P2M15D. The components are not normalized, only
+// nanoseconds are carried into and borrowed from the seconds, e.g.
+// PT0.75S for PT1S and -PT0.25S, see Normalize for carrying the rest. Negative durations are subtracted,
+// and a result whose components are all negative is returned as a
+// negative duration. Components of different signs are kept as they
+// are, see Validate.
+//
+// Add panics with ErrOutOfRange if a component overflows, see
+// AddChecked.
+func (d *Duration) Add(other *Duration) *Duration {
+	res, err := d.AddChecked(other)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// AddChecked works like Add, but returns ErrOutOfRange instead of
+// panicking if a component overflows
+func (d *Duration) AddChecked(other *Duration) (*Duration, error) {
+	a, b := d.signed(), other.signed()
+	ac, bc := a.components(), b.components()
+
+	var comps [7]int
+	for i := range comps {
+		v, ok := addInt(ac[i], bc[i])
+		if !ok {
+			return nil, ErrOutOfRange
+		}
+		comps[i] = v
+	}
+	nanos, ok := addInt(a.Nanoseconds, b.Nanoseconds)
+	if !ok {
+		return nil, ErrOutOfRange
+	}
+	return fromSigned(comps, nanos)
+}
+
+// fromSigned returns the duration of the signed components and
+// nanoseconds, which are carried into the seconds. Seconds and
+// nanoseconds form a single component, so they are made to share a
+// sign. Components which are all negative are folded into the Negative
+// flag.
+func fromSigned(comps [7]int, nanos int) (*Duration, error) {
+	var ok bool
+	if comps[6], ok = addInt(comps[6], nanos/1e9); !ok {
+		return nil, ErrOutOfRange
+	}
+	comps[6], nanos = shareSign(comps[6], nanos%1e9)
+
+	res := &Duration{Nanoseconds: nanos}
+	res.setComponents(comps)
+	if res.hasMixedSigns() {
+		return res, nil
+	}
+	for _, v := range comps {
+		// the Negative flag can not hold the magnitude
+		if v == math.MinInt {
+			return nil, ErrOutOfRange
+		}
+	}
+	return res.canonical(), nil
+}
+
+// Sub returns the difference of both durations, component by
+// component, like Add with other negated. The result may have mixed
+// signs, e.g. P1Y-3M for P1Y minus P3M, which Format rejects. Use
+// Validate to detect them and Normalize or NormalizeCalendar to carry
+// them into consistent components.
+//
+// Sub panics with ErrOutOfRange if a component overflows, see
+// SubChecked.
+func (d *Duration) Sub(other *Duration) *Duration {
+	res, err := d.SubChecked(other)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// SubChecked works like Sub, but returns ErrOutOfRange instead of
+// panicking if a component overflows
+func (d *Duration) SubChecked(other *Duration) (*Duration, error) {
+	if other == nil {
+		return d.AddChecked(nil)
+	}
+	neg := *other
+	neg.Negative = !neg.Negative
+	return d.AddChecked(&neg)
+}
+
+// Mul returns the duration with every component multiplied by n, e.g.
+// P12W for P2W times 6. A negative n negates the duration. The
+// components are not normalized, so PT30M times 4 is PT120M, see
+// Normalize for carrying it into PT2H. Only nanoseconds adding up to a
+// second are carried into the seconds.
+//
+// Mul panics with ErrOutOfRange if a component overflows, see
+// MulChecked.
+func (d *Duration) Mul(n int64) *Duration {
+	res, err := d.MulChecked(n)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// MulChecked works like Mul, but returns ErrOutOfRange instead of
+// panicking if a component overflows
+func (d *Duration) MulChecked(n int64) (*Duration, error) {
+	s := d.signed()
+
+	// any non-zero component overflows if n does not fit into an int
+	m := int(n)
+	if int64(m) != n {
+		if s.IsZero() {
+			return &Duration{}, nil
+		}
+		return nil, ErrOutOfRange
+	}
+
+	var comps [7]int
+	for i, v := range s.components() {
+		p, ok := mulInt(v, m)
+		if !ok {
+			return nil, ErrOutOfRange
+		}
+		comps[i] = p
+	}
+	nanos, ok := mulInt(s.Nanoseconds, m)
+	if !ok {
+		return nil, ErrOutOfRange
+	}
+	return fromSigned(comps, nanos)
+}
+
+// Div divides the duration into n equal parts and returns the length
+// of a part along with what is left over, e.g. PT8H for P1D divided by
+// 3. The components are divided one by one, starting with years.
+// Remainders are carried into the next smaller unit where that is
+// exact in calendar terms: years into months, weeks into days, days
+// into hours, assuming days of 24 hours, and so on down to
+// nanoseconds. Months can not be carried into days, so they remain in
+// the remainder, as do left over nanoseconds. For example, P1Y divided
+// by 4 is P3M, while P1M divided by 4 is zero with a remainder of P1M.
+//
+// The quotient multiplied by n plus the remainder adds up to d. The
+// remainder has the sign of d. Div returns ErrDivideByZero if n is
+// zero, ErrMixedSigns if the components of d have different signs and
+// ErrOutOfRange if a carry overflows or n is out of the range of the
+// components.
+func (d *Duration) Div(n int64) (quotient, remainder *Duration, err error) {
+	if n == 0 {
+		return nil, nil, ErrDivideByZero
+	}
+	if int64(int(n)) != n || int(n) == math.MinInt {
+		return nil, nil, ErrOutOfRange
+	}
+	if err = d.Validate(); err != nil {
+		return nil, nil, err
+	}
+	c := d.canonical()
+	if c == nil {
+		c = &Duration{}
+	}
+
+	m := int(n)
+	if m < 0 {
+		m = -m
+	}
+
+	comps := c.components()
+	vals := [8]int{comps[0], comps[1], comps[2], comps[3], comps[4], comps[5], comps[6], c.Nanoseconds}
+	var qs, rs [8]int
+	carry := 0
+	for i, v := range vals {
+		v, ok := addInt(v, carry)
+		if !ok {
+			return nil, nil, ErrOutOfRange
+		}
+		qs[i], carry = v/m, v%m
+		if carries[i] == 0 {
+			rs[i], carry = carry, 0
+		} else if carry, ok = mulInt(carry, carries[i]); !ok {
+			return nil, nil, ErrOutOfRange
+		}
+	}
+
+	quotient = &Duration{Nanoseconds: qs[7]}
+	quotient.setComponents([7]int{qs[0], qs[1], qs[2], qs[3], qs[4], qs[5], qs[6]})
+	quotient.Negative = c.Negative != (n < 0) && !quotient.IsZero()
+	remainder = &Duration{Nanoseconds: rs[7]}
+	remainder.setComponents([7]int{rs[0], rs[1], rs[2], rs[3], rs[4], rs[5], rs[6]})
+	remainder.Negative = c.Negative && !remainder.IsZero()
+	return quotient, remainder, nil
+}
+
+// Ratio returns how many times other fits into the duration, e.g. 2
+// for PT2H and PT1H, comparing them by ToEstimatedDuration. If other is
+// zero, Ratio follows float division and returns +Inf or -Inf with the
+// sign of the duration, or NaN if the duration is zero as well.
+func (d *Duration) Ratio(other *Duration) float64 {
+	return float64(d.ToEstimatedDuration()) / float64(other.ToEstimatedDuration())
+}
+
+// shareSign borrows a second if the seconds and the nanoseconds, which
+// are less than a second, have different signs, e.g. 0 and 5e8 for 1
+// and -5e8
+func shareSign(secs, nanos int) (int, int) {
+	switch {
+	case secs > 0 && nanos < 0:
+		return secs - 1, nanos + 1e9
+	case secs < 0 && nanos > 0:
+		return secs + 1, nanos - 1e9
+	}
+	return secs, nanos
+}
+
+// addInt returns a + b and whether the sum did not overflow
+func addInt(a, b int) (int, bool) {
+	c := a + b
+	return c, (c > a) == (b > 0)
+}
+
+// mulInt returns a * b and whether the product did not overflow
+func mulInt(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return c, false
+	}
+	return c, c/b == a
+}
@@ -0,0 +1,532 @@
+package iso8601duration
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinMax(t *testing.T) {
+	t.Parallel()
+
+	ds, err := ParseMany("P1D,PT2H,P1W,-PT1H,P1M", ",")
+	assert.NoError(t, err)
+
+	assert.Same(t, ds[3], Min(ds...))
+	assert.Same(t, ds[4], Max(ds...))
+
+	// a single element is both the minimum and the maximum
+	assert.Same(t, ds[0], Min(ds[0]))
+	assert.Same(t, ds[0], Max(ds[0]))
+
+	// ties go to the first duration
+	day, hours := &Duration{Days: 1}, &Duration{Hours: 24}
+	assert.Same(t, day, Min(day, hours))
+	assert.Same(t, day, Max(day, hours))
+
+	// nil entries are skipped
+	assert.Same(t, ds[1], Min(nil, ds[1], nil, ds[0]))
+	assert.Same(t, ds[0], Max(nil, ds[1], nil, ds[0]))
+
+	assert.Nil(t, Min())
+	assert.Nil(t, Max())
+	assert.Nil(t, Min(nil, nil))
+
+	// beyond the range of time.Duration
+	long, day := &Duration{Years: 300}, &Duration{Days: 1}
+	assert.Same(t, long, Max(day, long))
+	assert.Same(t, day, Min(day, long))
+}
+
+func TestMinOfMaxOf(t *testing.T) {
+	t.Parallel()
+
+	month, days := MustFromString("P1M"), MustFromString("P30D")
+	feb := time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Same(t, month, MinOf(feb, month, days))
+	assert.Same(t, days, MaxOf(feb, month, days))
+	assert.Same(t, days, MinOf(jul, month, days))
+	assert.Same(t, month, MaxOf(jul, month, days))
+
+	// ties go to the first duration, nil entries are skipped
+	apr := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	assert.Same(t, month, MinOf(apr, nil, month, days))
+	assert.Same(t, days, MaxOf(apr, days, nil, month))
+
+	assert.Nil(t, MinOf(feb))
+	assert.Nil(t, MaxOf(feb, nil))
+}
+
+func TestClamp(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC)
+	min, max := MustFromString("PT1M"), MustFromString("P30D")
+
+	for input, expected := range map[string]*Duration{
+		"PT30S":  min,
+		"-PT1H":  min,
+		"PT1M":   MustFromString("PT1M"),
+		"PT1H":   MustFromString("PT1H"),
+		"P1M":    MustFromString("P1M"),
+		"P30D":   MustFromString("P30D"),
+		"P31D":   max,
+		"P1Y":    max,
+		"PT721H": max,
+	} {
+		assert.Equal(t, expected, MustFromString(input).Clamp(min, max, at), input)
+	}
+
+	// the bounds are compared at the given time
+	month := MustFromString("P1M")
+	jul := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)
+	assert.Same(t, month, month.Clamp(min, max, at))
+	assert.Same(t, max, month.Clamp(min, max, jul))
+
+	// inverted bounds are swapped
+	d := MustFromString("P1Y")
+	assert.Same(t, max, d.Clamp(max, min, at))
+	d = MustFromString("PT1S")
+	assert.Same(t, min, d.Clamp(max, min, at))
+
+	// nil bounds are open
+	assert.Same(t, d, d.Clamp(nil, max, at))
+	assert.Same(t, min, d.Clamp(min, nil, at))
+	d = MustFromString("P1Y")
+	assert.Same(t, d, d.Clamp(min, nil, at))
+	assert.Same(t, d, d.Clamp(nil, nil, at))
+}
+
+func TestSum(t *testing.T) {
+	t.Parallel()
+
+	ds, err := ParseMany("P1D,P2D,PT1H", ",")
+	assert.NoError(t, err)
+	assert.Equal(t, "P3DT1H", Sum(ds...).String())
+
+	// components are not normalized
+	ds, err = ParseMany("PT40M,PT40M,P1W,P7D", ",")
+	assert.NoError(t, err)
+	assert.Equal(t, "P1W7DT80M", Sum(ds...).String())
+
+	// fractions are carried into the seconds
+	ds, err = ParseMany("PT0.75S,PT0.5S", ",")
+	assert.NoError(t, err)
+	assert.Equal(t, "PT1.25S", Sum(ds...).String())
+
+	// negative durations are subtracted
+	ds, err = ParseMany("P1DT2H,-P3DT2H", ",")
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{Negative: true, Days: 2}, Sum(ds...))
+
+	ds, err = ParseMany("P1D,-PT1H", ",")
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{Days: 1, Hours: -1}, Sum(ds...))
+
+	// the inputs are left untouched
+	d := &Duration{Days: 1}
+	assert.Equal(t, "P2D", Sum(d, d).String())
+	assert.Equal(t, &Duration{Days: 1}, d)
+
+	// nil entries are skipped
+	assert.Equal(t, &Duration{Hours: 1}, Sum(nil, &Duration{Hours: 1}, nil))
+
+	assert.Equal(t, &Duration{}, Sum())
+	assert.Equal(t, &Duration{}, Sum(nil))
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	a, err := FromString("P1M")
+	assert.NoError(t, err)
+	b, err := FromString("P2M15D")
+	assert.NoError(t, err)
+	assert.Equal(t, "P3M15D", a.Add(b).String())
+	assert.Equal(t, a.Add(b), b.Add(a))
+
+	// the inputs are left untouched
+	assert.Equal(t, &Duration{Months: 1}, a)
+	assert.Equal(t, &Duration{Months: 2, Days: 15}, b)
+
+	// composes with Normalize
+	c := (&Duration{Minutes: 50}).Add(&Duration{Minutes: 40, Seconds: 30})
+	assert.Equal(t, &Duration{Minutes: 90, Seconds: 30}, c)
+	assert.Equal(t, &Duration{Hours: 1, Minutes: 30, Seconds: 30}, c.Normalize())
+
+	// negative durations and components
+	assert.Equal(t, &Duration{Negative: true, Hours: 1}, (&Duration{Hours: 1}).Add(&Duration{Negative: true, Hours: 2}))
+	assert.Equal(t, &Duration{Negative: true, Hours: 3}, (&Duration{Hours: -1}).Add(&Duration{Negative: true, Hours: 2}))
+	assert.Equal(t, &Duration{Days: 1, Hours: -2}, (&Duration{Days: 1}).Add(&Duration{Negative: true, Hours: 2}))
+	assert.Equal(t, &Duration{}, (&Duration{Days: 1}).Add(&Duration{Days: -1}))
+
+	// nanoseconds are carried
+	assert.Equal(t, &Duration{Seconds: 1, Nanoseconds: 1}, (&Duration{Nanoseconds: 5e8}).Add(&Duration{Nanoseconds: 5e8 + 1}))
+
+	// nil is the zero duration
+	assert.Equal(t, &Duration{Days: 1}, (*Duration)(nil).Add(&Duration{Days: 1}))
+	assert.Equal(t, &Duration{Days: 1}, (&Duration{Days: 1}).Add(nil))
+}
+
+func TestAddChecked(t *testing.T) {
+	t.Parallel()
+
+	max := &Duration{Years: math.MaxInt}
+	one := &Duration{Years: 1}
+
+	d, err := max.AddChecked(&Duration{Years: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{Years: math.MaxInt - 1}, d)
+
+	d, err = max.AddChecked(one)
+	assert.Nil(t, d)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	assert.PanicsWithValue(t, ErrOutOfRange, func() { max.Add(one) })
+
+	min := &Duration{Seconds: math.MinInt}
+	_, err = min.AddChecked(&Duration{Seconds: -1})
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	_, err = min.AddChecked(&Duration{Negative: true, Seconds: 1})
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	_, err = min.AddChecked(nil)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	d, err = min.AddChecked(&Duration{Seconds: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{Negative: true, Seconds: math.MaxInt}, d)
+
+	// the carry from nanoseconds may overflow as well
+	_, err = (&Duration{Seconds: math.MaxInt, Nanoseconds: 6e8}).AddChecked(&Duration{Nanoseconds: 6e8})
+	assert.ErrorIs(t, err, ErrOutOfRange)
+
+	assert.Panics(t, func() { Sum(max, one) })
+}
+
+func TestSub(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b     string
+		expected Duration
+	}{
+		{"P1Y", "P3M", Duration{Years: 1, Months: -3}},
+		{"P3M", "P1M", Duration{Months: 2}},
+		{"P1M", "P3M", Duration{Negative: true, Months: 2}},
+		{"P1D", "PT1H", Duration{Days: 1, Hours: -1}},
+		{"PT1H", "PT30M", Duration{Hours: 1, Minutes: -30}},
+		// seconds and their fraction share a sign
+		{"PT1S", "PT0.25S", Duration{Nanoseconds: 750000000}},
+		{"PT0.25S", "PT1S", Duration{Negative: true, Nanoseconds: 750000000}},
+		{"PT2.25S", "PT0.5S", Duration{Seconds: 1, Nanoseconds: 750000000}},
+		{"PT1.5S", "PT2.75S", Duration{Negative: true, Seconds: 1, Nanoseconds: 250000000}},
+		{"-PT1S", "-PT0.5S", Duration{Negative: true, Nanoseconds: 500000000}},
+		{"PT1M", "PT0.5S", Duration{Minutes: 1, Nanoseconds: -500000000}},
+		{"PT1M1S", "PT0.5S", Duration{Minutes: 1, Nanoseconds: 500000000}},
+		{"PT1H", "-PT1H", Duration{Hours: 2}},
+		{"-PT1H", "PT1H", Duration{Negative: true, Hours: 2}},
+		{"P1DT2H", "P1DT2H", Duration{}},
+	} {
+		a, err := FromString(tc.a)
+		assert.NoError(t, err)
+		b, err := FromString(tc.b)
+		assert.NoError(t, err)
+
+		d := a.Sub(b)
+		assert.Equal(t, &tc.expected, d, "%s - %s", tc.a, tc.b)
+		assert.Equal(t, a.ToEstimatedDuration()-b.ToEstimatedDuration(), d.ToEstimatedDuration(), "%s - %s", tc.a, tc.b)
+	}
+
+	// borrowing is left to Normalize
+	d := (&Duration{Hours: 1}).Sub(&Duration{Minutes: 30})
+	assert.ErrorIs(t, d.Validate(), ErrMixedSigns)
+	assert.Equal(t, &Duration{Minutes: 30}, d.Normalize())
+
+	// including fractions borrowing from the minutes
+	d = MustFromString("PT1M").Sub(MustFromString("PT0.5S"))
+	assert.ErrorIs(t, d.Validate(), ErrMixedSigns)
+	assert.Equal(t, "PT59.5S", d.Normalize().String())
+
+	// while fractions of whole seconds format as they are
+	for _, tc := range [][3]string{
+		{"PT1S", "PT0.5S", "PT0.5S"},
+		{"PT5S", "PT0.3S", "PT4.7S"},
+		{"PT0.3S", "PT5S", "-PT4.7S"},
+		{"P1DT10S", "PT0.000000001S", "P1DT9.999999999S"},
+	} {
+		d := MustFromString(tc[0]).Sub(MustFromString(tc[1]))
+		formatted, err := d.Format()
+		assert.NoError(t, err, "%s - %s", tc[0], tc[1])
+		assert.Equal(t, tc[2], formatted, "%s - %s", tc[0], tc[1])
+	}
+
+	// and to NormalizeCalendar for calendar components
+	d = (&Duration{Years: 1}).Sub(&Duration{Months: 3})
+	assert.Equal(t, "P9M", d.NormalizeCalendar(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)).String())
+
+	assert.Equal(t, &Duration{Days: 1}, (&Duration{Days: 1}).Sub(nil))
+	assert.Equal(t, &Duration{Negative: true, Days: 1}, (*Duration)(nil).Sub(&Duration{Days: 1}))
+
+	_, err := (&Duration{Days: math.MinInt + 1}).SubChecked(&Duration{Days: 2})
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	assert.Panics(t, func() { (&Duration{Days: math.MaxInt}).Sub(&Duration{Negative: true, Days: 1}) })
+}
+
+func TestRatio(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b     string
+		expected float64
+	}{
+		{"PT2H", "PT1H", 2},
+		{"PT1H", "PT2H", 0.5},
+		{"P1D", "PT1H", 24},
+		{"P1W", "P1D", 7},
+		{"PT1.5S", "PT1S", 1.5},
+		{"-PT3M", "PT1M", -3},
+		{"-PT3M", "-PT1M", 3},
+		{"PT0S", "PT1M", 0},
+		{"PT1H", "PT0S", math.Inf(1)},
+		{"-PT1H", "PT0S", math.Inf(-1)},
+	} {
+		a, err := FromString(tc.a)
+		assert.NoError(t, err)
+		b, err := FromString(tc.b)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, a.Ratio(b), "%s / %s", tc.a, tc.b)
+	}
+
+	assert.Equal(t, math.Inf(1), (&Duration{Days: 1}).Ratio(nil))
+
+	// division by zero follows float division
+	assert.Equal(t, math.Inf(-1), (&Duration{Hours: -1}).Ratio(&Duration{Negative: true}))
+	assert.True(t, math.IsNaN((&Duration{}).Ratio(&Duration{})))
+	assert.True(t, math.IsNaN((*Duration)(nil).Ratio(nil)))
+	assert.Zero(t, (*Duration)(nil).Ratio(&Duration{Days: 1}))
+}
+
+func TestMul(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d        string
+		n        int64
+		expected string
+	}{
+		{"P2W", 6, "P12W"},
+		{"PT30M", 4, "PT120M"},
+		{"P1Y2M3W4DT5H6M7S", 2, "P2Y4M6W8DT10H12M14S"},
+		{"PT0.6S", 3, "PT1.8S"},
+		{"PT0.5S", 2, "PT1S"},
+		{"P1D", 0, "PT0S"},
+		{"P1D", 1, "P1D"},
+		{"P1D", -3, "-P3D"},
+		{"-PT1H", -2, "PT2H"},
+		{"-PT0.5S", 3, "-PT1.5S"},
+	} {
+		d, err := FromString(tc.d)
+		assert.NoError(t, err)
+
+		m := d.Mul(tc.n)
+		assert.Equal(t, tc.expected, m.String(), "%s * %d", tc.d, tc.n)
+		assert.Equal(t, d.ToEstimatedDuration()*time.Duration(tc.n), m.ToEstimatedDuration(), "%s * %d", tc.d, tc.n)
+	}
+
+	// normalization is up to the caller
+	assert.Equal(t, "PT2H", (&Duration{Minutes: 30}).Mul(4).Normalize().String())
+
+	// mixed signs are kept
+	assert.Equal(t, &Duration{Days: -2, Hours: 2}, (&Duration{Days: 1, Hours: -1}).Mul(-2))
+
+	assert.Equal(t, &Duration{}, (*Duration)(nil).Mul(3))
+}
+
+func TestMulChecked(t *testing.T) {
+	t.Parallel()
+
+	d, err := (&Duration{Years: math.MaxInt / 2}).MulChecked(2)
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{Years: math.MaxInt - 1}, d)
+
+	for _, tc := range []struct {
+		d Duration
+		n int64
+	}{
+		{Duration{Years: math.MaxInt/2 + 1}, 2},
+		{Duration{Seconds: 2}, math.MaxInt},
+		{Duration{Seconds: -1}, math.MinInt},
+		{Duration{Seconds: math.MinInt}, -1},
+		{Duration{Hours: 3}, math.MinInt / 2},
+		{Duration{Nanoseconds: 1e9 - 1}, math.MaxInt / 2},
+	} {
+		d, err := tc.d.MulChecked(tc.n)
+		assert.Nil(t, d, "%v * %d", tc.d, tc.n)
+		assert.ErrorIs(t, err, ErrOutOfRange, "%v * %d", tc.d, tc.n)
+	}
+
+	assert.Panics(t, func() { (&Duration{Days: math.MaxInt}).Mul(2) })
+
+	// a zero duration never overflows
+	d, err = (&Duration{}).MulChecked(math.MinInt64)
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{}, d)
+}
+
+func TestDiv(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2021, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		d         string
+		n         int64
+		quotient  string
+		remainder string
+	}{
+		{"P1Y", 4, "P3M", "PT0S"},
+		{"P1D", 3, "PT8H", "PT0S"},
+		{"PT1H", 7, "PT8M34.285714285S", "PT0.000000005S"},
+		{"P1Y", 5, "P2M", "P2M"},
+		{"P1M", 4, "PT0S", "P1M"},
+		{"P1M1W", 2, "P3DT12H", "P1M"},
+		{"P1Y1D", 2, "P6MT12H", "PT0S"},
+		{"PT1S", 3, "PT0.333333333S", "PT0.000000001S"},
+		{"P2W", 1, "P2W", "PT0S"},
+		{"P1D", -2, "-PT12H", "PT0S"},
+		{"-P1D", 2, "-PT12H", "PT0S"},
+		{"-P1D", -2, "PT12H", "PT0S"},
+		{"-P5M", 2, "-P2M", "-P1M"},
+	} {
+		d, err := FromString(tc.d)
+		assert.NoError(t, err)
+
+		q, r, err := d.Div(tc.n)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.quotient, q.String(), "%s / %d", tc.d, tc.n)
+		assert.Equal(t, tc.remainder, r.String(), "%s %% %d", tc.d, tc.n)
+
+		// the parts add up to the duration again
+		assert.True(t, d.AddTo(from).Equal(q.Mul(tc.n).Add(r).AddTo(from)), "%s / %d", tc.d, tc.n)
+	}
+
+	_, _, err := (&Duration{Days: 1}).Div(0)
+	assert.ErrorIs(t, err, ErrDivideByZero)
+
+	_, _, err = (&Duration{Days: 1, Hours: -1}).Div(2)
+	assert.ErrorIs(t, err, ErrMixedSigns)
+
+	_, _, err = (&Duration{Years: 1, Months: math.MaxInt}).Div(2)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+
+	// the divisor can not be negated within the components
+	_, _, err = (&Duration{Days: 1}).Div(math.MinInt64)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+
+	q, r, err := (*Duration)(nil).Div(3)
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{}, q)
+	assert.Equal(t, &Duration{}, r)
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"PT1H", "PT2H", -1},
+		{"PT2H", "PT1H", 1},
+		{"PT60M", "PT1H", 0},
+		{"P1D", "PT24H", 0},
+		{"P1W", "P6D", 1},
+		{"P1M", "P30D", 0},
+		{"P1M", "P31D", -1},
+		{"P1Y", "P12M", 1},
+		{"-PT1H", "PT1S", -1},
+		{"-PT1H", "-PT2H", 1},
+		{"PT0S", "-PT0S", 0},
+		// beyond the range of time.Duration
+		{"P300Y", "P1D", 1},
+		{"-P300Y", "P1D", -1},
+		{"P300Y", "P301Y", -1},
+		{"P9999Y", "P3600M", 1},
+		{"P300Y", "P3600M", 1},
+		{"P300Y", "P109500D", 0},
+		{"P300Y", "PT9460800000S", 0},
+		{"P300Y", "PT9460800000.000000001S", -1},
+	} {
+		a, err := FromString(tc.a)
+		assert.NoError(t, err)
+		b, err := FromString(tc.b)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tc.expected, a.Compare(b), "%s <=> %s", tc.a, tc.b)
+		assert.Equal(t, -tc.expected, b.Compare(a), "%s <=> %s", tc.b, tc.a)
+		assert.Equal(t, tc.expected < 0, a.Less(b), "%s < %s", tc.a, tc.b)
+	}
+
+	assert.Equal(t, 0, (*Duration)(nil).Compare(&Duration{}))
+	assert.True(t, (*Duration)(nil).Less(&Duration{Seconds: 1}))
+
+	// sorting
+	ds, err := ParseMany("P1D,PT1H,-PT1M,P1W,PT90M", ",")
+	assert.NoError(t, err)
+	sort.Slice(ds, func(i, j int) bool { return ds[i].Less(ds[j]) })
+	assert.Equal(t, []*Duration{{Negative: true, Minutes: 1}, {Hours: 1}, {Minutes: 90}, {Days: 1}, {Weeks: 1}}, ds)
+}
+
+func TestDurationsSort(t *testing.T) {
+	t.Parallel()
+
+	sorted, err := ParseMany("-P300Y,-P1D,-PT1M,PT0S,PT1S,PT59M,PT1H,PT90M,P1D,P1W,P1M,P1Y,P292Y,P300Y,P9999Y", ",")
+	assert.NoError(t, err)
+
+	ds := make([]*Duration, len(sorted))
+	copy(ds, sorted)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		rnd.Shuffle(len(ds), func(i, j int) { ds[i], ds[j] = ds[j], ds[i] })
+		sort.Sort(Durations(ds))
+		assert.Equal(t, sorted, ds)
+		assert.True(t, sort.IsSorted(Durations(ds)))
+	}
+
+	// equal estimates keep their order with a stable sort
+	day, hours := &Duration{Days: 1}, &Duration{Hours: 24}
+	ds = []*Duration{hours, day}
+	sort.Stable(Durations(ds))
+	assert.Same(t, hours, ds[0])
+	assert.Same(t, day, ds[1])
+}
+
+func TestCompareAt(t *testing.T) {
+	t.Parallel()
+
+	month, days := &Duration{Months: 1}, &Duration{Days: 30}
+	february := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	// the estimate considers them equal
+	assert.Equal(t, 0, month.Compare(days))
+
+	assert.Equal(t, -1, month.CompareAt(february, days))
+	assert.Equal(t, 1, days.CompareAt(february, month))
+	assert.Equal(t, 0, month.CompareAt(april, days))
+	assert.Equal(t, 1, month.CompareAt(july, days))
+	assert.Equal(t, -1, days.CompareAt(july, month))
+
+	// leap years
+	year, common := &Duration{Years: 1}, &Duration{Days: 365}
+	assert.Equal(t, 0, year.Compare(common))
+	assert.Equal(t, 0, year.CompareAt(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), common))
+	assert.Equal(t, 1, year.CompareAt(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), common))
+
+	assert.Equal(t, 0, (*Duration)(nil).CompareAt(july, &Duration{}))
+}
@@ -0,0 +1,142 @@
+package iso8601duration
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var alternative = regexp.MustCompile(`^(-)?P(\d{4})-(\d{2})-(\d{2})T(\d{2}):(\d{2}):(\d{2})(?:[.,](\d+))?$`)
+
+// FromStringAlternative parses the alternative format of ISO8601
+// durations, e.g. P0001-02-03T04:05:06 or P0000-00-00T00:00:01.5
+func FromStringAlternative(dur string) (*Duration, error) {
+	match := alternative.FindStringSubmatch(dur)
+	if match == nil {
+		return nil, ErrBadFormat
+	}
+
+	var vals [6]int
+	for i := range vals {
+		val, err := strconv.Atoi(match[i+2])
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+
+	d := &Duration{
+		Negative: match[1] != "",
+		Years:    vals[0],
+		Months:   vals[1],
+		Days:     vals[2],
+		Hours:    vals[3],
+		Minutes:  vals[4],
+		Seconds:  vals[5],
+	}
+	if match[8] != "" {
+		d.Nanoseconds = parseFraction(match[8])
+	}
+	if !d.fitsAlternative() {
+		return nil, ErrOutOfRange
+	}
+
+	return d, nil
+}
+
+// FormatAlternative returns the duration in the alternative format of
+// ISO8601, P<YYYY>-<MM>-<DD>T<hh>:<mm>:<ss>. Weeks are converted into
+// days first.
+//
+// ErrOutOfRange is returned if a component exceeds its field, that is
+// more than 9999 years, 12 months, 30 days, 24 hours, 59 minutes or 59
+// seconds. ErrMixedSigns is returned if components have mixed signs.
+func (d *Duration) FormatAlternative() (string, error) {
+	if d.hasMixedSigns() {
+		return "", ErrMixedSigns
+	}
+	return d.canonical().ExpandWeeksToDays().formatAlternative()
+}
+
+// FormatAlternativeNormalized works like FormatAlternative, but
+// carries overflowing seconds, minutes and hours into the next larger
+// unit and months into years first. Days are never carried into
+// months as the length of a month varies.
+func (d *Duration) FormatAlternativeNormalized() (string, error) {
+	if d.hasMixedSigns() {
+		return "", ErrMixedSigns
+	}
+	if d == nil {
+		return d.formatAlternative()
+	}
+	c := d.canonical().ExpandWeeksToDays()
+
+	c.Minutes += c.Seconds / 60
+	c.Seconds %= 60
+	c.Hours += c.Minutes / 60
+	c.Minutes %= 60
+	c.Days += c.Hours / 24
+	c.Hours %= 24
+	c.Years += c.Months / 12
+	c.Months %= 12
+
+	return c.formatAlternative()
+}
+
+func (d *Duration) formatAlternative() (string, error) {
+	if d == nil {
+		d = &Duration{}
+	}
+	if !d.fitsAlternative() {
+		return "", ErrOutOfRange
+	}
+
+	b := make([]byte, 0, 32)
+	if d.Negative {
+		b = append(b, '-')
+	}
+	b = append(b, 'P')
+	b = appendPadded(b, d.Years, 4)
+	b = append(b, '-')
+	b = appendPadded(b, d.Months, 2)
+	b = append(b, '-')
+	b = appendPadded(b, d.Days, 2)
+	b = append(b, 'T')
+	b = appendPadded(b, d.Hours, 2)
+	b = append(b, ':')
+	b = appendPadded(b, d.Minutes, 2)
+	b = append(b, ':')
+	b = appendPadded(b, d.Seconds, 2)
+	if d.Nanoseconds != 0 {
+		frac, digits := d.Nanoseconds, 9
+		for frac%10 == 0 {
+			frac /= 10
+			digits--
+		}
+		b = append(b, '.')
+		b = appendPadded(b, frac, digits)
+	}
+
+	return string(b), nil
+}
+
+func (d *Duration) fitsAlternative() bool {
+	return d.Weeks == 0 &&
+		d.Years >= 0 && d.Years <= 9999 &&
+		d.Months >= 0 && d.Months <= 12 &&
+		d.Days >= 0 && d.Days <= 30 &&
+		d.Hours >= 0 && d.Hours <= 24 &&
+		d.Minutes >= 0 && d.Minutes <= 59 &&
+		d.Seconds >= 0 && d.Seconds <= 59 &&
+		d.Nanoseconds >= 0 && d.Nanoseconds < 1e9
+}
+
+// appendPadded appends a non-negative number zero-padded to width
+func appendPadded(b []byte, val int, width int) []byte {
+	for w := 10; width > 1; width-- {
+		if val < w {
+			b = append(b, '0')
+		}
+		w *= 10
+	}
+	return strconv.AppendInt(b, int64(val), 10)
+}
@@ -0,0 +1,98 @@
+package iso8601duration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAlternative(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6}
+	s, err := d.FormatAlternative()
+	assert.Nil(t, err)
+	assert.Equal(t, "P0001-02-03T04:05:06", s)
+
+	s, err = (&Duration{}).FormatAlternative()
+	assert.Nil(t, err)
+	assert.Equal(t, "P0000-00-00T00:00:00", s)
+
+	s, err = (&Duration{Negative: true, Years: 2021, Days: 30}).FormatAlternative()
+	assert.Nil(t, err)
+	assert.Equal(t, "-P2021-00-30T00:00:00", s)
+
+	// weeks are converted into days
+	s, err = (&Duration{Weeks: 2, Days: 1}).FormatAlternative()
+	assert.Nil(t, err)
+	assert.Equal(t, "P0000-00-15T00:00:00", s)
+
+	for _, d := range []Duration{
+		{Years: 10000},
+		{Months: 13},
+		{Days: 31},
+		{Weeks: 5},
+		{Hours: 25},
+		{Minutes: 60},
+		{Seconds: 60},
+		{Seconds: -60},
+	} {
+		_, err = d.FormatAlternative()
+		assert.Equal(t, ErrOutOfRange, err, d.String())
+	}
+
+	// negative components make a negative duration
+	s, err = (&Duration{Seconds: -1}).FormatAlternative()
+	assert.Nil(t, err)
+	assert.Equal(t, "-P0000-00-00T00:00:01", s)
+
+	_, err = (&Duration{Days: 1, Seconds: -1}).FormatAlternative()
+	assert.Equal(t, ErrMixedSigns, err)
+}
+
+func TestFormatAlternativeNormalized(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Months: 14, Days: 2, Hours: 25, Minutes: 61, Seconds: 61}
+	s, err := d.FormatAlternativeNormalized()
+	assert.Nil(t, err)
+	assert.Equal(t, "P0001-02-03T02:02:01", s)
+
+	// days are not carried into months
+	d = Duration{Weeks: 5}
+	_, err = d.FormatAlternativeNormalized()
+	assert.Equal(t, ErrOutOfRange, err)
+}
+
+func TestFromStringAlternative(t *testing.T) {
+	t.Parallel()
+
+	d, err := FromStringAlternative("P0001-02-03T04:05:06")
+	assert.Nil(t, err)
+	assert.Equal(t, &Duration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6}, d)
+
+	d, err = FromStringAlternative("-P0000-00-01T00:00:00")
+	assert.Nil(t, err)
+	assert.Equal(t, &Duration{Negative: true, Days: 1}, d)
+
+	d, err = FromStringAlternative("P0000-00-00T00:00:01,5")
+	assert.Nil(t, err)
+	assert.Equal(t, &Duration{Seconds: 1, Nanoseconds: 500000000}, d)
+
+	for _, s := range []string{"P1Y", "P0001-02-03", "P1-2-3T4:5:6", "P0001-02-03T04:05:06Z"} {
+		_, err = FromStringAlternative(s)
+		assert.Equal(t, ErrBadFormat, err, s)
+	}
+
+	_, err = FromStringAlternative("P0000-13-00T00:00:00")
+	assert.Equal(t, ErrOutOfRange, err)
+
+	// round-trip
+	for _, s := range []string{"P0000-00-00T00:00:00", "P9999-12-30T24:59:59", "-P0010-06-15T12:30:00", "P0000-00-00T00:00:00.001"} {
+		d, err = FromStringAlternative(s)
+		assert.Nil(t, err)
+		out, err := d.FormatAlternative()
+		assert.Nil(t, err)
+		assert.Equal(t, s, out)
+	}
+}
@@ -0,0 +1,73 @@
+package iso8601duration
+
+// Builder assembles a Duration step by step, e.g.
+// NewBuilder().Years(1).Months(2).Build()
+type Builder struct {
+	d Duration
+}
+
+// NewBuilder returns a Builder starting at the zero duration
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Negative marks the duration as negative
+func (b *Builder) Negative() *Builder {
+	b.d.Negative = true
+	return b
+}
+
+// Years sets the years
+func (b *Builder) Years(v int) *Builder {
+	b.d.Years = v
+	return b
+}
+
+// Months sets the months
+func (b *Builder) Months(v int) *Builder {
+	b.d.Months = v
+	return b
+}
+
+// Weeks sets the weeks
+func (b *Builder) Weeks(v int) *Builder {
+	b.d.Weeks = v
+	return b
+}
+
+// Days sets the days
+func (b *Builder) Days(v int) *Builder {
+	b.d.Days = v
+	return b
+}
+
+// Hours sets the hours
+func (b *Builder) Hours(v int) *Builder {
+	b.d.Hours = v
+	return b
+}
+
+// Minutes sets the minutes
+func (b *Builder) Minutes(v int) *Builder {
+	b.d.Minutes = v
+	return b
+}
+
+// Seconds sets the seconds
+func (b *Builder) Seconds(v int) *Builder {
+	b.d.Seconds = v
+	return b
+}
+
+// Nanoseconds sets the fraction of a second
+func (b *Builder) Nanoseconds(v int) *Builder {
+	b.d.Nanoseconds = v
+	return b
+}
+
+// Build returns the assembled duration. The builder can be used
+// further without affecting the returned duration.
+func (b *Builder) Build() *Duration {
+	d := b.d
+	return &d
+}
@@ -0,0 +1,37 @@
+package iso8601duration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	d := NewBuilder().Years(1).Months(2).Build()
+	assert.Equal(t, &Duration{Years: 1, Months: 2}, d)
+
+	d = NewBuilder().
+		Weeks(3).
+		Days(4).
+		Hours(5).
+		Minutes(6).
+		Seconds(7).
+		Nanoseconds(8).
+		Negative().
+		Build()
+	assert.Equal(t, "-P3W4DT5H6M7.000000008S", d.String())
+
+	// empty build
+	assert.Equal(t, &Duration{}, NewBuilder().Build())
+
+	// building conditionally
+	b := NewBuilder().Days(1)
+	first := b.Build()
+	if first.Days > 0 {
+		b.Hours(12)
+	}
+	assert.Equal(t, &Duration{Days: 1}, first)
+	assert.Equal(t, &Duration{Days: 1, Hours: 12}, b.Build())
+}
@@ -0,0 +1,156 @@
+package iso8601duration
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNonexistentDay is returned with the Reject policy when the day of
+// the month does not exist in the month reached by adding years and
+// months, e.g. February 31
+var ErrNonexistentDay = errors.New("day does not exist in month")
+
+// MonthEndPolicy controls how days which do not exist in the target
+// month are handled when years and months are added
+type MonthEndPolicy int
+
+const (
+	// Rollover carries the excess days into the next month like
+	// time.AddDate, so January 31 plus P1M is March 3, or March 2 in
+	// leap years
+	Rollover MonthEndPolicy = iota
+	// ClampToMonthEnd moves the day to the last day of the target
+	// month, so January 31 plus P1M is the last day of February
+	ClampToMonthEnd
+	// Reject fails with ErrNonexistentDay
+	Reject
+)
+
+// CalendarOptions controls how durations are applied to dates. The
+// zero value works exactly like AddTo, ToDuration and Between.
+//
+// Years and months are applied together before the rest of the
+// duration, so the policy is applied once per call: P1M1D from January
+// 31, 2023 is March 1 with ClampToMonthEnd, that is February 28 plus
+// one day. Like with java.time, the day of the month is not remembered
+// across calls. Adding P1M twice to January 31 gives March 28 with
+// ClampToMonthEnd, while adding P2M gives March 31. Multiply the
+// duration and add it to the original date instead, as Occurrences
+// does, to keep the day of the month over a series of dates.
+type CalendarOptions struct {
+	// MonthEnd is the policy for days which do not exist in the target
+	// month
+	MonthEnd MonthEndPolicy
+}
+
+// AddTo works like Duration.AddTo, but applies the month end policy
+// after adding years and months. It returns ErrNonexistentDay with the
+// Reject policy.
+func (o CalendarOptions) AddTo(d *Duration, t time.Time) (time.Time, error) {
+	s := *d.signed()
+	t, err := o.addMonths(t, s.Years, s.Months)
+	if err != nil {
+		return time.Time{}, err
+	}
+	s.Years, s.Months = 0, 0
+	return s.AddTo(t), nil
+}
+
+// ToDuration works like Duration.ToDuration, but applies the month end
+// policy like AddTo
+func (o CalendarOptions) ToDuration(d *Duration, from time.Time) (time.Duration, error) {
+	t, err := o.AddTo(d, from)
+	if err != nil {
+		return 0, err
+	}
+	return t.Sub(from), nil
+}
+
+// Between works like the function Between, but takes months according
+// to the month end policy, so that AddTo with the same options lands
+// on b. From January 31 to February 28, 2023, it returns P1M with
+// ClampToMonthEnd and P28D otherwise. With Reject, months which would
+// reach a nonexistent day are not taken.
+func (o CalendarOptions) Between(a, b time.Time) *Duration {
+	b = b.In(a.Location())
+	if b.Before(a) {
+		d := o.Between(b, a.In(b.Location()))
+		d.Negative = true
+		return d
+	}
+	return o.span(a, b, 1)
+}
+
+// span breaks the time from a to b down into calendar components,
+// taken greedily from largest to smallest in the direction of sign, so
+// that the result added to a lands on b. b must not lie before a if
+// sign is 1, and not after a if sign is -1, in which case the result is
+// Negative.
+func (o CalendarOptions) span(a, b time.Time, sign int) *Duration {
+	// beyond reports whether t lies past b when coming from a
+	beyond := func(t time.Time) bool {
+		if sign < 0 {
+			return t.Before(b)
+		}
+		return t.After(b)
+	}
+
+	// the difference in calendar months is an upper bound
+	months := sign * ((b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month()))
+	mid := a
+	for ; months > 0; months-- {
+		t, err := o.addMonths(a, 0, sign*months)
+		if err == nil && !beyond(t) {
+			mid = t
+			break
+		}
+	}
+
+	// days can be off by one due to DST transitions
+	days := sign * int(b.Sub(mid)/(24*time.Hour))
+	for !beyond(mid.AddDate(0, 0, sign*(days+1))) {
+		days++
+	}
+	for days > 0 && beyond(mid.AddDate(0, 0, sign*days)) {
+		days--
+	}
+	mid = mid.AddDate(0, 0, sign*days)
+
+	rem := b.Sub(mid) * time.Duration(sign)
+
+	return &Duration{
+		Negative:    sign < 0,
+		Years:       months / 12,
+		Months:      months % 12,
+		Days:        days,
+		Hours:       int(rem / time.Hour),
+		Minutes:     int(rem % time.Hour / time.Minute),
+		Seconds:     int(rem % time.Minute / time.Second),
+		Nanoseconds: int(rem % time.Second),
+	}
+}
+
+// addMonths adds years and months to t according to the month end
+// policy
+func (o CalendarOptions) addMonths(t time.Time, years, months int) (time.Time, error) {
+	if o.MonthEnd == Rollover || (years == 0 && months == 0) {
+		return t.AddDate(years, months, 0), nil
+	}
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+
+	// the first of the target month, normalized by time.Date
+	first := time.Date(year+years, month+time.Month(months), 1, hour, min, sec, t.Nanosecond(), t.Location())
+	if last := daysIn(first.Year(), first.Month()); day > last {
+		if o.MonthEnd == Reject {
+			return time.Time{}, ErrNonexistentDay
+		}
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, hour, min, sec, t.Nanosecond(), t.Location()), nil
+}
+
+// daysIn returns the number of days in the month of the year
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
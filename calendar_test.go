@@ -0,0 +1,191 @@
+package iso8601duration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarOptionsAddTo(t *testing.T) {
+	t.Parallel()
+
+	rollover := CalendarOptions{}
+	clamp := CalendarOptions{MonthEnd: ClampToMonthEnd}
+	reject := CalendarOptions{MonthEnd: Reject}
+
+	for _, tc := range []struct {
+		from     time.Time
+		d        string
+		rollover time.Time
+		clamp    time.Time
+		// rejected is set if Reject fails, otherwise it yields clamp
+		rejected bool
+	}{
+		{date(2023, time.January, 31, 10, 0, 0), "P1M", date(2023, time.March, 3, 10, 0, 0), date(2023, time.February, 28, 10, 0, 0), true},
+		{date(2024, time.January, 31, 10, 0, 0), "P1M", date(2024, time.March, 2, 10, 0, 0), date(2024, time.February, 29, 10, 0, 0), true},
+		{date(2024, time.January, 30, 10, 0, 0), "P1M", date(2024, time.March, 1, 10, 0, 0), date(2024, time.February, 29, 10, 0, 0), true},
+		{date(2024, time.January, 29, 10, 0, 0), "P1M", date(2024, time.February, 29, 10, 0, 0), date(2024, time.February, 29, 10, 0, 0), false},
+		{date(2023, time.January, 15, 10, 0, 0), "P1M", date(2023, time.February, 15, 10, 0, 0), date(2023, time.February, 15, 10, 0, 0), false},
+		{date(2023, time.March, 31, 10, 0, 0), "P1M", date(2023, time.May, 1, 10, 0, 0), date(2023, time.April, 30, 10, 0, 0), true},
+		{date(2023, time.August, 31, 10, 0, 0), "P1M", date(2023, time.October, 1, 10, 0, 0), date(2023, time.September, 30, 10, 0, 0), true},
+		{date(2023, time.January, 31, 10, 0, 0), "P2M", date(2023, time.March, 31, 10, 0, 0), date(2023, time.March, 31, 10, 0, 0), false},
+		{date(2023, time.December, 31, 10, 0, 0), "P2M", date(2024, time.March, 2, 10, 0, 0), date(2024, time.February, 29, 10, 0, 0), true},
+		{date(2023, time.January, 31, 10, 0, 0), "P12M", date(2024, time.January, 31, 10, 0, 0), date(2024, time.January, 31, 10, 0, 0), false},
+
+		// leap days
+		{date(2024, time.February, 29, 10, 0, 0), "P1Y", date(2025, time.March, 1, 10, 0, 0), date(2025, time.February, 28, 10, 0, 0), true},
+		{date(2024, time.February, 29, 10, 0, 0), "P4Y", date(2028, time.February, 29, 10, 0, 0), date(2028, time.February, 29, 10, 0, 0), false},
+		{date(2024, time.February, 29, 10, 0, 0), "P12M", date(2025, time.March, 1, 10, 0, 0), date(2025, time.February, 28, 10, 0, 0), true},
+
+		// years and months are applied together
+		{date(2023, time.January, 31, 10, 0, 0), "P1Y1M", date(2024, time.March, 2, 10, 0, 0), date(2024, time.February, 29, 10, 0, 0), true},
+
+		// the rest is added after the policy is applied
+		{date(2023, time.January, 31, 10, 0, 0), "P1M1D", date(2023, time.March, 4, 10, 0, 0), date(2023, time.March, 1, 10, 0, 0), true},
+		{date(2023, time.January, 31, 10, 0, 0), "P1M1W", date(2023, time.March, 10, 10, 0, 0), date(2023, time.March, 7, 10, 0, 0), true},
+		{date(2023, time.January, 31, 10, 0, 0), "P1MT12H", date(2023, time.March, 3, 10, 0, 0).Add(12 * time.Hour), date(2023, time.February, 28, 10, 0, 0).Add(12 * time.Hour), true},
+
+		// durations without months are not affected
+		{date(2023, time.January, 31, 10, 0, 0), "P1W", date(2023, time.February, 7, 10, 0, 0), date(2023, time.February, 7, 10, 0, 0), false},
+		{date(2023, time.January, 31, 10, 0, 0), "PT24H", date(2023, time.February, 1, 10, 0, 0), date(2023, time.February, 1, 10, 0, 0), false},
+
+		// negative durations
+		{date(2023, time.March, 31, 10, 0, 0), "-P1M", date(2023, time.March, 3, 10, 0, 0), date(2023, time.February, 28, 10, 0, 0), true},
+		{date(2023, time.January, 31, 10, 0, 0), "-P1M", date(2022, time.December, 31, 10, 0, 0), date(2022, time.December, 31, 10, 0, 0), false},
+		{date(2023, time.May, 31, 10, 0, 0), "-P1Y3M", date(2022, time.March, 3, 10, 0, 0), date(2022, time.February, 28, 10, 0, 0), true},
+		{date(2024, time.March, 31, 10, 0, 0), "-P1M1D", date(2024, time.March, 1, 10, 0, 0), date(2024, time.February, 28, 10, 0, 0), true},
+	} {
+		d := MustFromString(tc.d)
+
+		res, err := rollover.AddTo(d, tc.from)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.rollover, res, "rollover %s from %s", tc.d, tc.from)
+		assert.Equal(t, d.AddTo(tc.from), res, "%s from %s", tc.d, tc.from)
+
+		res, err = clamp.AddTo(d, tc.from)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.clamp, res, "clamp %s from %s", tc.d, tc.from)
+
+		res, err = reject.AddTo(d, tc.from)
+		if tc.rejected {
+			assert.ErrorIs(t, err, ErrNonexistentDay, "reject %s from %s", tc.d, tc.from)
+			assert.True(t, res.IsZero())
+		} else if assert.NoError(t, err, "reject %s from %s", tc.d, tc.from) {
+			assert.Equal(t, tc.clamp, res, "reject %s from %s", tc.d, tc.from)
+		}
+	}
+
+	// components with a negative sign work like the Negative flag
+	res, err := clamp.AddTo(&Duration{Months: -1}, date(2023, time.March, 31, 10, 0, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, date(2023, time.February, 28, 10, 0, 0), res)
+}
+
+func TestCalendarOptionsComposition(t *testing.T) {
+	t.Parallel()
+
+	clamp := CalendarOptions{MonthEnd: ClampToMonthEnd}
+	start := time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC)
+	month := MustFromString("P1M")
+
+	// the day of the month is not remembered across calls
+	feb, err := clamp.AddTo(month, start)
+	assert.NoError(t, err)
+	mar, err := clamp.AddTo(month, feb)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.March, 28, 0, 0, 0, 0, time.UTC), mar)
+
+	// multiplying keeps it
+	mar, err = clamp.AddTo(month.Mul(2), start)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC), mar)
+
+	// as do Occurrences
+	o := month.Occurrences(start)
+	o.Limit = 3
+	assert.Equal(t, []time.Time{
+		start,
+		time.Date(2023, time.February, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}, collect(o, 10))
+}
+
+func TestCalendarOptionsToDuration(t *testing.T) {
+	t.Parallel()
+
+	day := 24 * time.Hour
+	from := time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC)
+	d := MustFromString("P1M")
+
+	res, err := CalendarOptions{}.ToDuration(d, from)
+	assert.NoError(t, err)
+	assert.Equal(t, 31*day, res)
+	assert.Equal(t, d.ToDuration(from), res)
+
+	res, err = CalendarOptions{MonthEnd: ClampToMonthEnd}.ToDuration(d, from)
+	assert.NoError(t, err)
+	assert.Equal(t, 28*day, res)
+
+	res, err = CalendarOptions{MonthEnd: Reject}.ToDuration(d, from)
+	assert.ErrorIs(t, err, ErrNonexistentDay)
+	assert.Zero(t, res)
+
+	// the wall-clock time is kept across DST transitions
+	berlin := loadLocation(t, "Europe/Berlin")
+	from = time.Date(2023, time.January, 31, 12, 0, 0, 0, berlin)
+	res, err = CalendarOptions{MonthEnd: ClampToMonthEnd}.ToDuration(MustFromString("P2M"), from)
+	assert.NoError(t, err)
+	assert.Equal(t, 59*day-time.Hour, res)
+	res, err = CalendarOptions{MonthEnd: ClampToMonthEnd}.ToDuration(MustFromString("P2M1D"), from.AddDate(0, 0, -3))
+	assert.NoError(t, err)
+	assert.Equal(t, 60*day-time.Hour, res)
+}
+
+func TestCalendarOptionsBetween(t *testing.T) {
+	t.Parallel()
+
+	rollover := CalendarOptions{}
+	clamp := CalendarOptions{MonthEnd: ClampToMonthEnd}
+	reject := CalendarOptions{MonthEnd: Reject}
+
+	for _, tc := range []struct {
+		a, b                    time.Time
+		rollover, clamp, reject string
+	}{
+		{date(2023, time.January, 31, 0, 0, 0), date(2023, time.February, 28, 0, 0, 0), "P28D", "P1M", "P28D"},
+		{date(2024, time.January, 31, 0, 0, 0), date(2024, time.February, 29, 0, 0, 0), "P29D", "P1M", "P29D"},
+		{date(2023, time.January, 31, 0, 0, 0), date(2023, time.March, 1, 0, 0, 0), "P29D", "P1M1D", "P29D"},
+		{date(2023, time.January, 31, 0, 0, 0), date(2023, time.March, 5, 0, 0, 0), "P1M2D", "P1M5D", "P33D"},
+		{date(2023, time.January, 31, 0, 0, 0), date(2023, time.March, 31, 0, 0, 0), "P2M", "P2M", "P2M"},
+		{date(2023, time.January, 15, 0, 0, 0), date(2023, time.March, 18, 0, 0, 0), "P2M3D", "P2M3D", "P2M3D"},
+		{date(2024, time.February, 29, 0, 0, 0), date(2025, time.February, 28, 0, 0, 0), "P11M30D", "P1Y", "P11M30D"},
+		{date(2024, time.February, 29, 0, 0, 0), date(2028, time.February, 29, 0, 0, 0), "P4Y", "P4Y", "P4Y"},
+		{date(2023, time.March, 31, 0, 0, 0), date(2023, time.April, 30, 0, 0, 0), "P30D", "P1M", "P30D"},
+		{date(2023, time.January, 31, 0, 0, 0), date(2023, time.January, 31, 0, 0, 0), "PT0S", "PT0S", "PT0S"},
+
+		// a after b
+		{date(2023, time.February, 28, 0, 0, 0), date(2023, time.January, 31, 0, 0, 0), "-P28D", "-P1M", "-P28D"},
+	} {
+		for _, p := range []struct {
+			o        CalendarOptions
+			expected string
+		}{
+			{rollover, tc.rollover},
+			{clamp, tc.clamp},
+			{reject, tc.reject},
+		} {
+			d := p.o.Between(tc.a, tc.b)
+			assert.Equal(t, p.expected, d.String(), "%+v from %s to %s", p.o, tc.a, tc.b)
+
+			// adding the result to a lands on b
+			if tc.a.After(tc.b) {
+				continue
+			}
+			res, err := p.o.AddTo(d, tc.a)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.b, res, "%+v from %s to %s", p.o, tc.a, tc.b)
+		}
+		assert.Equal(t, Between(tc.a, tc.b), rollover.Between(tc.a, tc.b))
+	}
+}
@@ -0,0 +1,20 @@
+package iso8601duration
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout works like context.WithTimeout, but the deadline is
+// computed with AddTo from the current time, so P1M expires on the same
+// day of the next month. Zero and negative durations yield a context
+// which is already expired rather than an error.
+func WithTimeout(ctx context.Context, d *Duration) (context.Context, context.CancelFunc) {
+	return WithDeadlineFrom(ctx, time.Now(), d)
+}
+
+// WithDeadlineFrom works like WithTimeout, but starts counting at from
+// instead of the current time. The deadline is exactly d.AddTo(from).
+func WithDeadlineFrom(ctx context.Context, from time.Time, d *Duration) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(ctx, d.AddTo(from))
+}
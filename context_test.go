@@ -0,0 +1,65 @@
+package iso8601duration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDeadlineFrom(t *testing.T) {
+	t.Parallel()
+
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	// 2021-03-28 is only 23 hours long in Berlin
+	from := time.Date(2021, 3, 27, 12, 0, 0, 0, berlin)
+	for _, s := range []string{"P1D", "PT24H", "P1M", "P1Y2M3DT4H5M6.5S"} {
+		d := MustFromString(s)
+		ctx, cancel := WithDeadlineFrom(context.Background(), from, d)
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok, s)
+		assert.True(t, d.AddTo(from).Equal(deadline), "%s: %s", s, deadline)
+		assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded, s)
+		cancel()
+	}
+
+	ctx, cancel := WithDeadlineFrom(context.Background(), from, MustFromString("P1D"))
+	defer cancel()
+	d, _ := ctx.Deadline()
+	assert.True(t, time.Date(2021, 3, 28, 12, 0, 0, 0, berlin).Equal(d))
+	assert.Equal(t, 23*time.Hour, d.Sub(from))
+
+	// an earlier deadline of the parent is kept
+	parent, cancelParent := context.WithDeadline(context.Background(), from.Add(time.Hour))
+	defer cancelParent()
+	ctx, cancel = WithDeadlineFrom(parent, from, MustFromString("P1D"))
+	defer cancel()
+	d, _ = ctx.Deadline()
+	assert.True(t, from.Add(time.Hour).Equal(d))
+}
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now()
+	d := MustFromString("P1M")
+	ctx, cancel := WithTimeout(context.Background(), d)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.False(t, deadline.Before(d.AddTo(before)))
+	assert.False(t, deadline.After(d.AddTo(time.Now())))
+	assert.NoError(t, ctx.Err())
+
+	// zero and negative durations are already expired
+	for _, s := range []string{"PT0S", "-PT1S"} {
+		ctx, cancel := WithTimeout(context.Background(), MustFromString(s))
+		assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded, s)
+		cancel()
+	}
+	ctx, cancel = WithTimeout(context.Background(), nil)
+	defer cancel()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
@@ -2,36 +2,84 @@
 package iso8601duration
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"regexp"
 	"strconv"
-	"text/template"
 	"time"
 )
 
+// designators is the grammar of FromString after the optional sign
+const designators = `P((?P<Y>\d+)Y)?((?P<M>\d+)M)?((?P<W>\d+)W)?((?P<D>\d+)D)?(T((?P<TH>\d+)H)?((?P<TM>\d+)M)?((?P<TS>\d+)([.,](?P<frac>\d+))?S)?)?`
+
+const rfc3339Time = `T((\d+H(\d+M(\d+(\.\d+)?S)?)?)|(\d+M(\d+(\.\d+)?S)?)|(\d+(\.\d+)?S))`
+
 var (
 	// ErrBadFormat is returned when parsing fails
 	ErrBadFormat = errors.New("bad format string")
 
-	tmpl = template.Must(template.New("duration").
-		Parse(
-			`P{{if .Years}}{{.Years}}Y{{end}}` +
-				`{{if .Months}}{{.Months}}M{{end}}` +
-				`{{if .Weeks}}{{.Weeks}}W{{end}}` +
-				`{{if .Days}}{{.Days}}D{{end}}` +
-				`{{if .HasTimePart}}T{{end}}` +
-				`{{if .Hours}}{{.Hours}}H{{end}}` +
-				`{{if .Minutes}}{{.Minutes}}M{{end}}` +
-				`{{if .Seconds}}{{.Seconds}}S{{end}}`,
-		),
-	)
+	// ErrEmpty is returned when parsing a string without any component,
+	// that is "", "P" or "PT"
+	ErrEmpty = errors.New("empty duration")
+
+	// ErrUnknownUnit is returned by Get and Set for units which are
+	// not a component of a duration
+	ErrUnknownUnit = errors.New("unknown unit")
+
+	// ErrDivideByZero is returned by Div when dividing by zero
+	ErrDivideByZero = errors.New("division by zero")
+
+	// ErrTooLong is returned by FromStringLimited when the input
+	// exceeds the length limit
+	ErrTooLong = errors.New("duration string too long")
+
+	// ErrNotFixed is returned when a duration contains years or months,
+	// which have no fixed length
+	ErrNotFixed = errors.New("duration has no fixed length")
+
+	// ErrNotWholeMonths is returned by TotalMonthsStrict when a duration
+	// contains components smaller than a month
+	ErrNotWholeMonths = errors.New("duration is not a whole number of months")
 
-	full = regexp.MustCompile(`P((?P<year>\d+)Y)?((?P<month>\d+)M)?((?P<week>\d+)W)?((?P<day>\d+)D)?(T((?P<hour>\d+)H)?((?P<minute>\d+)M)?((?P<second>\d+)S)?)?`)
+	// ErrOutOfRange is returned when a value does not fit where it has
+	// to go: a component into its field in the alternative format, the
+	// result of Add, Mul, Div, Round, FromSeconds or TotalMonthsStrict
+	// into an integer, or the nanoseconds into a second, see Validate
+	ErrOutOfRange = errors.New("component out of range")
+
+	// rfc3339 follows the grammar in appendix A of RFC 3339 without
+	// dur-week, which only allows consecutive units
+	rfc3339 = regexp.MustCompile(`^P(((\d+Y(\d+M(\d+D)?)?)|(\d+M(\d+D)?)|(\d+D))(` + rfc3339Time + `)?|` + rfc3339Time + `)$`)
+
+	// full is anchored so that anything but a single, ordered occurrence
+	// of each designator is rejected. The groups are named after their
+	// designator, prefixed with T in the time part. Values are plain
+	// ASCII digits, so exponents, signs and separators like in P1e2D,
+	// P+1D or P1_000D never match. A single sign is only accepted in
+	// front of the P designator, so ++P1D and -+P1D do not match either.
+	full = regexp.MustCompile(`^(?P<sign>[-+])?` + designators + `$`)
+
+	// find is the unanchored counterpart of full used by FindString.
+	// Durations have to start and end at word boundaries.
+	find = regexp.MustCompile(`(?P<sign>[-+])?\b` + designators + `\b`)
 )
 
+// Duration is an ISO8601 duration.
+//
+// A nil *Duration is treated like the zero duration: IsZero reports
+// true, the other predicates false and conversions return zero. Methods
+// which return a modified copy, like Negate, Normalize or DatePart,
+// return nil, while arithmetic and rounding, like Add, Mul, Round,
+// Truncate and TopN, as well as Split, return a zero duration. Only
+// the printers which never fail, like String and AppendTo, differ and
+// print "<nil>", following fmt.
 type Duration struct {
+	// Negative marks the whole duration as negative, e.g. -P1D.
+	// The components are expected to be non-negative in that case.
+	Negative bool
+
 	Years   int
 	Months  int
 	Weeks   int
@@ -39,94 +87,628 @@ type Duration struct {
 	Hours   int
 	Minutes int
 	Seconds int
+	// Nanoseconds holds the fraction of a second, e.g. 500000000 for
+	// PT0.5S. It is expected to stay below one second.
+	Nanoseconds int
+}
+
+// FromTimeDuration converts a time.Duration into hours, minutes,
+// seconds and nanoseconds. Hours are not carried into days, so the
+// result is exact: its ToEstimatedDuration equals td.
+func FromTimeDuration(td time.Duration) *Duration {
+	d := &Duration{
+		Hours:       int(td / time.Hour),
+		Minutes:     int(td % time.Hour / time.Minute),
+		Seconds:     int(td % time.Minute / time.Second),
+		Nanoseconds: int(td % time.Second),
+	}
+	if td < 0 {
+		return d.canonical()
+	}
+	return d
+}
+
+// FromGoDuration parses a string in the format of time.ParseDuration,
+// e.g. "1h30m", and converts it like FromTimeDuration, so "90m" becomes
+// PT1H30M as well. ErrBadFormat is returned if time.ParseDuration
+// fails.
+func FromGoDuration(s string) (*Duration, error) {
+	td, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, ErrBadFormat
+	}
+	return FromTimeDuration(td), nil
+}
+
+// FromSeconds converts a number of seconds into a duration of seconds
+// only, e.g. PT90.5S for 90.5. The fraction is rounded to the nearest
+// nanosecond. ErrOutOfRange is returned if secs is NaN or does not fit
+// into the Seconds field.
+func FromSeconds(secs float64) (*Duration, error) {
+	if math.IsNaN(secs) || secs >= math.MaxInt64 || secs <= math.MinInt64 {
+		return nil, ErrOutOfRange
+	}
+	whole, frac := math.Modf(secs)
+	d := &Duration{
+		Seconds:     int(whole),
+		Nanoseconds: int(math.Round(frac * 1e9)),
+	}
+	if d.Nanoseconds == 1e9 || d.Nanoseconds == -1e9 {
+		d.Seconds += d.Nanoseconds / 1e9
+		d.Nanoseconds = 0
+	}
+	return d.canonical(), nil
+}
+
+// SplitOptions controls how FromTimeDurationOpts and NormalizeOpts
+// carry hours into larger units. The zero value keeps everything in
+// hours.
+type SplitOptions struct {
+	// Days splits every 24 hours into a day. This assumes that days
+	// are 24 hours long, so the result only applies to date arithmetic
+	// where that holds.
+	Days bool
+	// Weeks splits every 7 days into a week. It implies Days.
+	Weeks bool
+}
+
+// FromTimeDurationOpts works like FromTimeDuration, but can split the
+// hours into days and weeks, e.g. 193h into P1W1DT1H. Negative
+// durations are split the same way as positive ones.
+func FromTimeDurationOpts(td time.Duration, opts SplitOptions) *Duration {
+	d := FromTimeDuration(td)
+	if opts.Days || opts.Weeks {
+		d.Days = d.Hours / 24
+		d.Hours %= 24
+	}
+	if opts.Weeks {
+		d.Weeks = d.Days / 7
+		d.Days %= 7
+	}
+	return d
+}
+
+// FromStringRFC3339 parses a duration strictly following appendix A
+// of RFC 3339, as used by the duration format of OpenAPI. On top of
+// FromString, it rejects weeks, signs and skipped units between the
+// largest and smallest unit, e.g. P1Y1D. Fractional seconds are
+// accepted, as they are commonly used.
+func FromStringRFC3339(dur string) (*Duration, error) {
+	if isEmpty(dur) {
+		return nil, ErrEmpty
+	}
+	if !rfc3339.MatchString(dur) {
+		return nil, ErrBadFormat
+	}
+	return FromString(dur)
 }
 
+// FromString parses a duration. On top of ISO 8601-1, it accepts a
+// leading minus or plus sign, weeks combined with other components and leading
+// zeros, see FromStringWithInfo to detect them.
 func FromString(dur string) (*Duration, error) {
+	d, _, err := FromStringWithInfo(dur)
+	return d, err
+}
+
+// ParseInfo describes how a parsed string deviates from ISO 8601-1
+type ParseInfo struct {
+	// StrictlyConformant is set if the string follows ISO 8601-1
+	// without any of the deviations below
+	StrictlyConformant bool
+	// Signed is set if the string starts with a minus or plus sign,
+	// which is an extension of ISO 8601-2
+	Signed bool
+	// MixedWeeks is set if weeks are combined with other components,
+	// e.g. P1W2D, while ISO 8601-1 only allows weeks on their own
+	MixedWeeks bool
+	// LeadingZeros is set if a component has superfluous leading
+	// zeros, e.g. P01D
+	LeadingZeros bool
+	// EmptyTimePart is set if the T designator is not followed by any
+	// time component, e.g. P1DT, while ISO 8601-1 requires T to be
+	// omitted then
+	EmptyTimePart bool
+}
+
+// FromStringWithInfo works like FromString, but also reports whether
+// the string was strictly conformant to ISO 8601-1
+func FromStringWithInfo(dur string) (*Duration, ParseInfo, error) {
 	var (
 		match []string
 		re    *regexp.Regexp
+		info  ParseInfo
 	)
 
+	if isEmpty(dur) {
+		return nil, info, ErrEmpty
+	}
+
 	if full.MatchString(dur) {
 		match = full.FindStringSubmatch(dur)
 		re = full
 	} else {
-		return nil, ErrBadFormat
+		return nil, info, ErrBadFormat
 	}
 
 	d := &Duration{}
+	components := 0
 
 	for i, name := range re.SubexpNames() {
 		part := match[i]
 		if i == 0 || name == "" || part == "" {
 			continue
 		}
+		if name == "sign" {
+			d.Negative = part == "-"
+			info.Signed = true
+			continue
+		}
+		if name == "frac" {
+			d.Nanoseconds = parseFraction(part)
+			continue
+		}
 
+		components++
+		if len(part) > 1 && part[0] == '0' {
+			info.LeadingZeros = true
+		}
 		val, err := strconv.Atoi(part)
 		if err != nil {
-			return nil, err
-		}
-		switch name {
-		case "year":
-			d.Years = val
-		case "month":
-			d.Months = val
-		case "week":
-			d.Weeks = val
-		case "day":
-			d.Days = val
-		case "hour":
-			d.Hours = val
-		case "minute":
-			d.Minutes = val
-		case "second":
-			d.Seconds = val
-		default:
-			return nil, errors.New(fmt.Sprintf("unknown field %s", name))
-		}
-	}
-
-	return d, nil
-}
-
-// String prints out the value passed in. It's not strictly according to the
-// ISO spec, but it's pretty close. In particular, to completely conform it
-// would need to round up to the next largest unit. 61 seconds to 1 minute 1
-// second, for example. It would also need to disallow weeks mingling with
-// other units.
-func (d *Duration) String() string {
-	var s bytes.Buffer
-
-	err := tmpl.Execute(&s, d)
+			return nil, ParseInfo{}, err
+		}
+		afterT := name[0] == 'T'
+		field := d.field(DesignatorMeaning(name[len(name)-1], afterT))
+		if field == nil {
+			return nil, ParseInfo{}, errors.New(fmt.Sprintf("unknown field %s", name))
+		}
+		*field = val
+	}
+
+	info.MixedWeeks = match[re.SubexpIndex("W")] != "" && components > 1
+	// the time part is optional as a whole, so a T without any time
+	// component matches the grammar
+	info.EmptyTimePart = dur[len(dur)-1] == 'T'
+	info.StrictlyConformant = !info.Signed && !info.MixedWeeks && !info.LeadingZeros && !info.EmptyTimePart
+	return d, info, nil
+}
+
+// MustFromString works like FromString, but panics if dur cannot be
+// parsed. It simplifies the initialization of package-level variables
+// and tests.
+func MustFromString(dur string) *Duration {
+	d, err := FromString(dur)
 	if err != nil {
-		panic(err)
+		panic(`iso8601duration: FromString(` + strconv.Quote(dur) + `): ` + err.Error())
+	}
+	return d
+}
+
+// FromStringLimited works like FromString, but returns ErrTooLong
+// without parsing if dur is longer than maxLen bytes. This bounds the
+// work spent on untrusted input.
+func FromStringLimited(dur string, maxLen int) (*Duration, error) {
+	if len(dur) > maxLen {
+		return nil, ErrTooLong
+	}
+	return FromString(dur)
+}
+
+// FromStringFlexible works like FromString, but also accepts a plain
+// integer as a number of seconds, e.g. PT30S for "30" or -PT30S for
+// "-30". This lets command line flags accept both "30" and "PT30S".
+func FromStringFlexible(dur string) (*Duration, error) {
+	secs, err := strconv.Atoi(dur)
+	if err != nil {
+		return FromString(dur)
+	}
+	if secs == math.MinInt {
+		return nil, ErrOutOfRange
+	}
+	if secs < 0 {
+		return &Duration{Negative: true, Seconds: -secs}, nil
+	}
+	return &Duration{Seconds: secs}, nil
+}
+
+// FindString locates the first duration within s, e.g. PT30S in
+// "backoff=PT30S retry", and returns it along with the matched text.
+// The duration has to be delimited by non-word characters, so P1D is
+// not found in "XP1D". ErrBadFormat is returned if s contains no
+// duration.
+func FindString(s string) (*Duration, string, error) {
+	for _, m := range find.FindAllString(s, -1) {
+		if isEmpty(m) {
+			continue
+		}
+		d, err := FromString(m)
+		if err != nil {
+			return nil, "", err
+		}
+		return d, m, nil
+	}
+	return nil, "", ErrBadFormat
+}
+
+// isEmpty reports whether dur is a duration string without any
+// component
+func isEmpty(dur string) bool {
+	switch dur {
+	case "", "P", "PT", "-P", "-PT", "+P", "+PT":
+		return true
+	}
+	return false
+}
+
+// parseFraction converts the digits after the decimal separator into
+// nanoseconds. Digits beyond nanosecond precision are truncated.
+func parseFraction(digits string) int {
+	nanos := 0
+	for i := 0; i < 9; i++ {
+		nanos *= 10
+		if i < len(digits) {
+			nanos += int(digits[i] - '0')
+		}
+	}
+	return nanos
+}
+
+// DesignatorMeaning returns the unit a designator stands for, e.g.
+// "years" for Y. M means "months" before the T designator and
+// "minutes" after it. An empty string is returned for unknown
+// designators and for designators which are not allowed on the given
+// side of T.
+func DesignatorMeaning(designator byte, afterT bool) string {
+	if afterT {
+		switch designator {
+		case 'H':
+			return "hours"
+		case 'M':
+			return "minutes"
+		case 'S':
+			return "seconds"
+		}
+		return ""
+	}
+
+	switch designator {
+	case 'Y':
+		return "years"
+	case 'M':
+		return "months"
+	case 'W':
+		return "weeks"
+	case 'D':
+		return "days"
+	}
+	return ""
+}
+
+// IsZero reports whether all components are zero, e.g. for P0D or
+// -PT0S. The Negative flag is not taken into account.
+func (d *Duration) IsZero() bool {
+	return d.components() == [7]int{} && (d == nil || d.Nanoseconds == 0)
+}
+
+// Get returns the component named by unit, which is one of "years",
+// "months", "weeks", "days", "hours", "minutes", "seconds" and
+// "nanoseconds". ErrUnknownUnit is returned for any other unit.
+func (d *Duration) Get(unit string) (int, error) {
+	if d == nil {
+		d = &Duration{}
+	}
+	field := d.field(unit)
+	if field == nil {
+		return 0, ErrUnknownUnit
+	}
+	return *field, nil
+}
+
+// Set sets the component named by unit like Get
+func (d *Duration) Set(unit string, v int) error {
+	field := d.field(unit)
+	if field == nil {
+		return ErrUnknownUnit
 	}
+	*field = v
+	return nil
+}
 
-	return s.String()
+// field returns a pointer to the component named by unit, or nil if
+// there is no such component
+func (d *Duration) field(unit string) *int {
+	switch unit {
+	case "years":
+		return &d.Years
+	case "months":
+		return &d.Months
+	case "weeks":
+		return &d.Weeks
+	case "days":
+		return &d.Days
+	case "hours":
+		return &d.Hours
+	case "minutes":
+		return &d.Minutes
+	case "seconds":
+		return &d.Seconds
+	case "nanoseconds":
+		return &d.Nanoseconds
+	}
+	return nil
 }
 
 func (d *Duration) HasTimePart() bool {
-	return d.Hours != 0 || d.Minutes != 0 || d.Seconds != 0
+	if d == nil {
+		return false
+	}
+	return d.Hours != 0 || d.Minutes != 0 || d.Seconds != 0 || d.Nanoseconds != 0
+}
+
+func (d *Duration) HasDatePart() bool {
+	if d == nil {
+		return false
+	}
+	return d.Years != 0 || d.Months != 0 || d.Weeks != 0 || d.Days != 0
+}
+
+// IsCalendarOnly reports whether the duration consists of whole days
+// or larger units only, so it can be applied with date arithmetic
+func (d *Duration) IsCalendarOnly() bool {
+	return d.HasDatePart() && !d.HasTimePart()
+}
+
+// DatePart returns a copy of the duration containing only the
+// components before the T designator
+func (d *Duration) DatePart() *Duration {
+	if d == nil {
+		return nil
+	}
+	return &Duration{
+		Negative: d.Negative,
+
+		Years:  d.Years,
+		Months: d.Months,
+		Weeks:  d.Weeks,
+		Days:   d.Days,
+	}
+}
+
+// TimePart returns a copy of the duration containing only the
+// components after the T designator
+func (d *Duration) TimePart() *Duration {
+	if d == nil {
+		return nil
+	}
+	return &Duration{
+		Negative: d.Negative,
+
+		Hours:       d.Hours,
+		Minutes:     d.Minutes,
+		Seconds:     d.Seconds,
+		Nanoseconds: d.Nanoseconds,
+	}
+}
+
+// Split separates the duration into its calendar part, which holds
+// years, months, weeks and days like DatePart, and the time part as an
+// exact time.Duration, e.g. P1M2D and 3h30m for P1M2DT3H30M. A negative
+// duration yields a negative calendar part and a negative time.Duration.
+// Combine reverses Split.
+func (d *Duration) Split() (calendar *Duration, exact time.Duration) {
+	if d == nil {
+		return &Duration{}, 0
+	}
+	calendar = d.DatePart()
+	calendar.Negative = d.Negative && !calendar.IsZero()
+	return calendar, d.TimePart().ToEstimatedDuration()
+}
+
+// Combine adds exact to calendar as hours, minutes, seconds and
+// nanoseconds, e.g. P1M2DT3H30M for P1M2D and 3h30m. It reverses Split,
+// except that the time part comes back carried into hours, so PT90M
+// turns into PT1H30M.
+// Like with Add, the time part of calendar is added up with exact, and
+// the result may have mixed signs if calendar and exact have different
+// signs.
+func Combine(calendar *Duration, exact time.Duration) *Duration {
+	return calendar.Add(FromTimeDuration(exact))
+}
+
+// FoldDaysIntoWeeks returns a copy of the duration with every 7 days
+// converted into a week, e.g. P16D becomes P2W2D
+func (d *Duration) FoldDaysIntoWeeks() *Duration {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.Weeks += c.Days / 7
+	c.Days %= 7
+	return &c
+}
+
+// ExpandWeeksToDays returns a copy of the duration with all weeks
+// converted into days, e.g. P2W2D becomes P16D
+func (d *Duration) ExpandWeeksToDays() *Duration {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.Days += c.Weeks * 7
+	c.Weeks = 0
+	return &c
+}
+
+// estimatedLengths holds the lengths of the components as assumed by
+// ToEstimatedDuration, ordered like components
+var estimatedLengths = [7]time.Duration{
+	YearCommon,
+	MonthCommon,
+	time.Hour * 24 * 7,
+	time.Hour * 24,
+	time.Hour,
+	time.Minute,
+	time.Second,
 }
 
 // ToEstimatedDuration returns an inaccurate duration that
-// is independent of when counting is started
+// is independent of when counting is started. Years are assumed to
+// last YearCommon and months MonthCommon, see Estimator for other
+// lengths.
 func (d *Duration) ToEstimatedDuration() time.Duration {
-	day := time.Hour * 24
-	month := day * 30
-	year := day * 365
+	return Estimator{}.Estimate(d)
+}
+
+// ToEstimatedDurationRounded works like ToEstimatedDuration, but
+// rounds the result to the nearest multiple of unit, with halfway
+// values rounded away from zero, e.g. 2s for PT1.5S and a unit of a
+// second. If unit is not positive, the result is not rounded. As
+// Normalize does not change the estimated duration, it does not matter
+// whether the duration is normalized before.
+func (d *Duration) ToEstimatedDurationRounded(unit time.Duration) time.Duration {
+	return d.ToEstimatedDuration().Round(unit)
+}
+
+// FitsInTimeDuration reports whether ToEstimatedDuration can represent
+// the duration without overflowing, which is not the case beyond about
+// 292 years, e.g. for P99999Y.
+func (d *Duration) FitsInTimeDuration() bool {
+	return Estimator{}.total(d).IsInt64()
+}
+
+// TotalMonths returns the years and months of the duration in months,
+// e.g. 14 for P1Y2M. Weeks, days and the time part are ignored, see
+// TotalMonthsStrict. Totals beyond the range of int64 saturate.
+func (d *Duration) TotalMonths() int64 {
+	months, err := d.totalMonths()
+	if err != nil {
+		if d.signed().Years < 0 {
+			return math.MinInt64
+		}
+		return math.MaxInt64
+	}
+	return months
+}
+
+// TotalMonthsStrict works like TotalMonths, but returns
+// ErrNotWholeMonths if the duration contains components smaller than
+// a month and ErrOutOfRange if the total does not fit into an int64
+func (d *Duration) TotalMonthsStrict() (int64, error) {
+	if d != nil && (d.Weeks != 0 || d.Days != 0 || d.HasTimePart()) {
+		return 0, ErrNotWholeMonths
+	}
+	return d.totalMonths()
+}
+
+func (d *Duration) totalMonths() (int64, error) {
+	d = d.signed()
+	years, months := int64(d.Years), int64(d.Months)
+	if years > math.MaxInt64/12 || years < math.MinInt64/12 {
+		return 0, ErrOutOfRange
+	}
+	total := years * 12
+	if (months > 0 && total > math.MaxInt64-months) || (months < 0 && total < math.MinInt64-months) {
+		return 0, ErrOutOfRange
+	}
+	return total + months, nil
+}
+
+// TotalDaysEstimate returns the duration in days, using the same
+// inaccurate lengths as ToEstimatedDuration, e.g. 30.5 for P1MT12H
+func (d *Duration) TotalDaysEstimate() float64 {
+	return d.TotalSeconds() / estimatedLengths[3].Seconds()
+}
+
+// AsSecondsOnly returns a copy of the duration with all components
+// collapsed into seconds, e.g. PT90061S for P1DT1H1M1S. The fraction
+// stays in Nanoseconds and shares the sign of the seconds. Years and
+// months are converted with the same inaccurate lengths as in
+// ToEstimatedDuration.
+//
+// AsSecondsOnly panics with ErrOutOfRange if the seconds overflow, see
+// AsSecondsOnlyChecked.
+func (d *Duration) AsSecondsOnly() *Duration {
+	res, err := d.AsSecondsOnlyChecked()
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// AsSecondsOnlyChecked works like AsSecondsOnly, but returns
+// ErrOutOfRange instead of panicking if the seconds overflow
+func (d *Duration) AsSecondsOnlyChecked() (*Duration, error) {
+	if d == nil {
+		return nil, nil
+	}
+	s := d.signed()
+
+	secs := 0
+	for i, v := range s.components() {
+		p, ok := mulInt(v, int(estimatedLengths[i]/time.Second))
+		if ok {
+			secs, ok = addInt(secs, p)
+		}
+		if !ok {
+			return nil, ErrOutOfRange
+		}
+	}
+	return fromSigned([7]int{6: secs}, s.Nanoseconds)
+}
+
+// TotalWeeks returns the weeks and days of the duration in weeks,
+// e.g. 1.5 for P1W3DT12H. The time part is added as a fraction of a
+// week, assuming days of 24 hours. Years and months are ignored.
+func (d *Duration) TotalWeeks() float64 {
+	d = d.signed()
+	days := float64(d.Weeks*7 + d.Days)
+	rest := d.TimePart().ToEstimatedDuration()
+	return (days + float64(rest)/float64(estimatedLengths[3])) / 7
+}
+
+// TotalSeconds returns the duration in seconds, e.g. for recording
+// metrics. It uses the same inaccurate lengths as ToEstimatedDuration,
+// that is months of 30 days and years of 365 days, but does not
+// overflow for durations beyond 292 years. The methods are named
+// after the totals, as Seconds, Minutes and Hours are fields.
+func (d *Duration) TotalSeconds() float64 {
+	if d == nil {
+		return 0
+	}
+	secs := float64(d.Nanoseconds) / 1e9
+	for i, v := range d.components() {
+		secs += float64(v) * estimatedLengths[i].Seconds()
+	}
+	if d.Negative {
+		return -secs
+	}
+	return secs
+}
 
-	tot := time.Duration(0)
+// TotalMinutes returns the duration in minutes like TotalSeconds
+func (d *Duration) TotalMinutes() float64 {
+	return d.TotalSeconds() / 60
+}
+
+// TotalHours returns the duration in hours like TotalSeconds
+func (d *Duration) TotalHours() float64 {
+	return d.TotalSeconds() / 3600
+}
 
-	tot += year * time.Duration(d.Years)
-	tot += month * time.Duration(d.Months)
-	tot += day * 7 * time.Duration(d.Weeks)
-	tot += day * time.Duration(d.Days)
-	tot += time.Hour * time.Duration(d.Hours)
-	tot += time.Minute * time.Duration(d.Minutes)
-	tot += time.Second * time.Duration(d.Seconds)
+// TotalSecondsAt returns the exact duration in seconds when starting
+// at from, as computed by ToDuration
+func (d *Duration) TotalSecondsAt(from time.Time) float64 {
+	return d.ToDuration(from).Seconds()
+}
 
-	return tot
+// FixedInterval returns the duration as a fixed-length interval.
+// Weeks, days and the time part are considered to be of fixed length,
+// so ErrNotFixed is returned if the duration contains years or months.
+func (d *Duration) FixedInterval() (time.Duration, error) {
+	if d != nil && (d.Years != 0 || d.Months != 0) {
+		return 0, ErrNotFixed
+	}
+	return d.ToEstimatedDuration(), nil
 }
 
 // ToDuration returns an accurate duration based on the current
@@ -135,12 +717,374 @@ func (d *Duration) ToEstimatedDuration() time.Duration {
 // This method aims to return a duration that will exactly hit the
 // expected time and date.
 func (d *Duration) ToDuration(from time.Time) time.Duration {
-	targetTime := from.
+	return d.AddTo(from).Sub(from)
+}
+
+// ToDurationIn works like ToDuration, but converts from into loc
+// first, so that days are counted in loc regardless of the location of
+// from. This matters when scheduling in a fixed time zone: P1D spans
+// 23 hours in Europe/Berlin on the day DST starts, but 24 hours in UTC.
+//
+// As with AddTo, years, months, weeks and days are applied in
+// wall-clock terms in loc and the time part in elapsed time. P1D from
+// noon thus ends at noon the next day, which is 23 hours later when
+// the clocks spring forward in between and 25 hours later when they
+// fall back, while PT24H is always 24 hours and ends at 13:00 or 11:00
+// wall-clock time on those days.
+func (d *Duration) ToDurationIn(from time.Time, loc *time.Location) time.Duration {
+	return d.ToDuration(from.In(loc))
+}
+
+// ToDurationBefore returns the length of the window which ends at
+// until, e.g. for retention policies. As months vary in length, it
+// differs from ToDuration: P1M before March 30 is 28 days in 2021,
+// while P1M from March 30 is 31 days.
+func (d *Duration) ToDurationBefore(until time.Time) time.Duration {
+	return until.Sub(d.SubtractFrom(until))
+}
+
+// ToDurationClamped works like ToDuration, but limits the magnitude of
+// the result to max. Durations too long for time.Duration saturate
+// before clamping, so they never wrap around.
+func (d *Duration) ToDurationClamped(from time.Time, max time.Duration) time.Duration {
+	if max < 0 {
+		max = -max
+	}
+	dur := d.ToDuration(from)
+	if dur > max {
+		return max
+	}
+	if dur < -max {
+		return -max
+	}
+	return dur
+}
+
+// ToGoString returns the duration in the syntax of time.ParseDuration,
+// e.g. 26h3m4s. Calendar components are expanded into hours, exactly
+// using ToDuration if an anchor is passed and using
+// ToEstimatedDuration otherwise.
+func (d *Duration) ToGoString(anchor ...time.Time) string {
+	if len(anchor) > 0 {
+		return d.ToDuration(anchor[0]).String()
+	}
+	return d.ToEstimatedDuration().String()
+}
+
+// AddTo returns the time at which the duration has passed when
+// starting at t. Years, months, weeks and days are applied with
+// AddDate in the location of t, so they keep the wall-clock time
+// across DST transitions, e.g. P1D from noon lands on noon the next
+// day even if that day is only 23 hours long. The time part is added
+// as elapsed time, saturating at the range of time.Duration.
+//
+// ToDuration is derived from AddTo, so t.Add(d.ToDuration(t)) is the
+// same instant. Adding ToEstimatedDuration instead assumes days of
+// 24 hours and drifts by the DST offset.
+func (d *Duration) AddTo(t time.Time) time.Time {
+	d = d.signed()
+	return t.
 		AddDate(d.Years, d.Months, 0).
 		AddDate(0, 0, 7*d.Weeks).
 		AddDate(0, 0, d.Days).
-		Add(time.Duration(d.Hours) * time.Hour).
-		Add(time.Duration(d.Minutes) * time.Minute).
-		Add(time.Duration(d.Seconds) * time.Second)
-	return targetTime.Sub(from)
+		Add(d.timePart(1))
+}
+
+// timePart returns the hours, minutes, seconds and nanoseconds of the
+// signed duration times sign. Sums beyond the range of time.Duration
+// saturate, so they never wrap around.
+func (d *Duration) timePart(sign int64) time.Duration {
+	// the estimate is far more precise than the margin to the limits of
+	// time.Duration, and if the sum fits, it comes out exact even if the
+	// products wrap around
+	est := (float64(d.Hours)*3600+float64(d.Minutes)*60+float64(d.Seconds))*1e9 + float64(d.Nanoseconds)
+	if math.Abs(est) < 1<<62 {
+		tot := time.Duration(d.Hours)*time.Hour +
+			time.Duration(d.Minutes)*time.Minute +
+			time.Duration(d.Seconds)*time.Second +
+			time.Duration(d.Nanoseconds)
+		return time.Duration(sign) * tot
+	}
+
+	tot := big.NewInt(int64(d.Nanoseconds))
+	for _, c := range []struct {
+		v    int
+		unit time.Duration
+	}{
+		{d.Hours, time.Hour},
+		{d.Minutes, time.Minute},
+		{d.Seconds, time.Second},
+	} {
+		v := big.NewInt(int64(c.v))
+		tot.Add(tot, v.Mul(v, big.NewInt(int64(c.unit))))
+	}
+	tot.Mul(tot, big.NewInt(sign))
+	if !tot.IsInt64() {
+		if tot.Sign() < 0 {
+			return math.MinInt64
+		}
+		return math.MaxInt64
+	}
+	return time.Duration(tot.Int64())
+}
+
+// AppliedOffsets reports how a duration was resolved by AppliedTo
+type AppliedOffsets struct {
+	// Months is the number of months applied, including twelve for
+	// every year
+	Months int
+	// MonthDays is the number of calendar days the years and months
+	// resolved to, e.g. 28 for P1M from February 1, 2023 and 31 from
+	// January 1. Days rolling over at the end of the month are
+	// included, so P1M from January 31, 2023 resolves to 31 days.
+	MonthDays int
+	// Days is the number of calendar days applied in total, that is
+	// MonthDays plus the weeks and days of the duration
+	Days int
+	// Elapsed is the time elapsed between the start and the result,
+	// as returned by ToDuration
+	Elapsed time.Duration
+}
+
+// AppliedTo works like AddTo, but also reports the offsets the duration
+// resolved to when starting at from. Calendar days are counted in the
+// location of from.
+func (d *Duration) AppliedTo(from time.Time) (time.Time, AppliedOffsets) {
+	s := d.signed()
+	months := from.AddDate(s.Years, s.Months, 0)
+	days := months.AddDate(0, 0, 7*s.Weeks+s.Days)
+	res := d.AddTo(from)
+	return res, AppliedOffsets{
+		Months:    12*s.Years + s.Months,
+		MonthDays: civilDays(from, months),
+		Days:      civilDays(from, days),
+		Elapsed:   res.Sub(from),
+	}
+}
+
+// civilDays returns the number of calendar days from the date of a to
+// the date of b
+func civilDays(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	da := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	db := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da) / (24 * time.Hour))
+}
+
+// SubtractFrom returns the time at which the duration has to start
+// to pass at t, e.g. the issue date of a token expiring at t. It
+// reverses AddTo by applying the negated components in the opposite
+// order: the time part first, then days and weeks, then years and
+// months.
+//
+// Months are subtracted with AddDate, so days which do not exist in
+// the target month roll over into the next one: March 31 minus P1M
+// is March 3 (or March 2 in leap years), as February 31 does not
+// exist.
+func (d *Duration) SubtractFrom(t time.Time) time.Time {
+	d = d.signed()
+	return t.
+		Add(d.timePart(-1)).
+		AddDate(0, 0, -d.Days).
+		AddDate(0, 0, -7*d.Weeks).
+		AddDate(-d.Years, -d.Months, 0)
+}
+
+// Progress returns the fraction of the duration that has elapsed at
+// now when starting at start. The result is clamped to [0, 1].
+func (d *Duration) Progress(start, now time.Time) float64 {
+	total := d.AddTo(start).Sub(start)
+	if total == 0 {
+		if now.Before(start) {
+			return 0
+		}
+		return 1
+	}
+
+	p := float64(now.Sub(start)) / float64(total)
+	if p < 0 {
+		return 0
+	}
+	if p > 1 {
+		return 1
+	}
+	return p
+}
+
+// Abs returns a copy of the duration with a positive sign, e.g. P1D
+// for -P1D or for Duration{Days: -1}.
+//
+// Components of different signs are carried first with NormalizeOpts,
+// including days and weeks, so P1DT-2H becomes PT22H. If calendar
+// components still disagree, e.g. in P1M-1D, the sign is taken from
+// ToEstimatedDuration and the components are negated one by one if
+// that is negative. Use NormalizeCalendar beforehand to resolve them
+// exactly.
+func (d *Duration) Abs() *Duration {
+	if d == nil {
+		return nil
+	}
+	if d.hasMixedSigns() {
+		d = d.NormalizeOpts(SplitOptions{Weeks: true})
+	}
+	if d.hasMixedSigns() {
+		if d.ToEstimatedDuration() < 0 {
+			return d.Negate()
+		}
+		return d
+	}
+	c := *d.canonical()
+	c.Negative = false
+	return &c
+}
+
+// Negate returns a copy of the duration with the opposite sign, e.g.
+// -P1D for P1D and vice versa. Components which are all negative are
+// folded into the Negative flag first, so the result formats with a
+// leading minus where needed. Components of different signs are
+// negated one by one, e.g. P-1DT1H for P1DT-1H.
+func (d *Duration) Negate() *Duration {
+	if d == nil {
+		return nil
+	}
+	if d.hasMixedSigns() {
+		c := *d
+		comps := c.components()
+		for i := range comps {
+			comps[i] = -comps[i]
+		}
+		c.setComponents(comps)
+		c.Nanoseconds = -c.Nanoseconds
+		return &c
+	}
+	c := *d.canonical()
+	c.Negative = !c.Negative
+	return &c
+}
+
+// Validate checks that the duration can be represented faithfully.
+// It returns ErrMixedSigns if some components are positive while
+// others are negative, e.g. Duration{Hours: 5, Minutes: -10}, and
+// ErrOutOfRange if Nanoseconds exceeds a second. Components which are
+// all negative are valid and are treated as a negative duration.
+func (d *Duration) Validate() error {
+	if d.hasMixedSigns() {
+		return ErrMixedSigns
+	}
+	if d != nil && (d.Nanoseconds >= 1e9 || d.Nanoseconds <= -1e9) {
+		return ErrOutOfRange
+	}
+	return nil
+}
+
+// canonical returns the duration with negative components folded into
+// the Negative flag, e.g. -P1D for Duration{Days: -1}. The duration is
+// expected to have no mixed signs.
+func (d *Duration) canonical() *Duration {
+	if d == nil || !d.hasNegative() {
+		return d
+	}
+	c := *d
+	comps := c.components()
+	for i := range comps {
+		comps[i] = -comps[i]
+	}
+	c.setComponents(comps)
+	c.Nanoseconds = -c.Nanoseconds
+	c.Negative = !c.Negative
+	return &c
+}
+
+func (d *Duration) hasNegative() bool {
+	for _, v := range d.components() {
+		if v < 0 {
+			return true
+		}
+	}
+	return d != nil && d.Nanoseconds < 0
+}
+
+// components returns the components of the duration ordered from the
+// largest to the smallest unit
+func (d *Duration) components() [7]int {
+	if d == nil {
+		return [7]int{}
+	}
+	return [...]int{d.Years, d.Months, d.Weeks, d.Days, d.Hours, d.Minutes, d.Seconds}
+}
+
+// setComponents sets the components from an array ordered like
+// components
+func (d *Duration) setComponents(c [7]int) {
+	d.Years, d.Months, d.Weeks, d.Days = c[0], c[1], c[2], c[3]
+	d.Hours, d.Minutes, d.Seconds = c[4], c[5], c[6]
+}
+
+// signed returns a copy of the duration with the Negative flag folded
+// into the components
+func (d *Duration) signed() *Duration {
+	if d == nil {
+		return &Duration{}
+	}
+	if !d.Negative {
+		return d
+	}
+	return &Duration{
+		Years:       -d.Years,
+		Months:      -d.Months,
+		Weeks:       -d.Weeks,
+		Days:        -d.Days,
+		Hours:       -d.Hours,
+		Minutes:     -d.Minutes,
+		Seconds:     -d.Seconds,
+		Nanoseconds: -d.Nanoseconds,
+	}
+}
+
+// Between returns the duration between a and b broken down into
+// calendar components, the way a human would describe it, e.g. P2M3D.
+// Components are taken greedily from largest to smallest, so that
+// adding the result to a lands on b if a is not after b.
+//
+// If a is after b, the result is the span from b to a marked as
+// Negative. All components are kept positive and the whole duration
+// is negated, so Between(b, a).Abs() equals Between(a, b). As the
+// components are taken from b, adding such a result to a does not
+// necessarily land on b: Between(March 3, January 31) is -P1M in 2021,
+// as January 31 plus P1M is March 3, but March 3 minus P1M is
+// February 3. Use NormalizeCalendar to take the components backwards
+// from a instead.
+//
+// b is converted into the location of a.
+func Between(a, b time.Time) *Duration {
+	return CalendarOptions{}.Between(a, b)
+}
+
+// Until returns the duration from now until t broken down into
+// calendar components like Between, e.g. the time left until a
+// certificate expires. It is negative if t lies in the past. Calendar
+// days are counted in the location of t.
+func Until(t time.Time) *Duration {
+	return UntilWithClock(t, realClock{})
+}
+
+// UntilWithClock works like Until, but takes the current time from
+// clock
+func UntilWithClock(t time.Time, clock Clock) *Duration {
+	return Between(clock.Now().In(t.Location()), t)
+}
+
+// Since returns the duration from t until now broken down into calendar
+// components like Between, e.g. the age of a record. It is negative if
+// t lies in the future, so Since(t) is Until(t) negated. Calendar days
+// are counted in the location of t.
+func Since(t time.Time) *Duration {
+	return SinceWithClock(t, realClock{})
+}
+
+// SinceWithClock works like Since, but takes the current time from
+// clock
+func SinceWithClock(t time.Time, clock Clock) *Duration {
+	return Between(t, clock.Now())
 }
@@ -1,8 +1,14 @@
 package iso8601duration
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -37,7 +43,7 @@ func TestString(t *testing.T) {
 
 	// test empty
 	d := Duration{}
-	assert.Equal(t, d.String(), "P")
+	assert.Equal(t, d.String(), "PT0S")
 
 	// test only larger-than-day
 	d = Duration{Years: 1, Days: 2}
@@ -60,6 +66,84 @@ func TestString(t *testing.T) {
 	assert.Equal(t, d.String(), "P1W")
 }
 
+// legacyTmpl is the text/template String() used to be implemented with.
+// It is kept around to make sure the output did not change.
+var legacyTmpl = template.Must(template.New("duration").
+	Parse(
+		`P{{if .Years}}{{.Years}}Y{{end}}` +
+			`{{if .Months}}{{.Months}}M{{end}}` +
+			`{{if .Weeks}}{{.Weeks}}W{{end}}` +
+			`{{if .Days}}{{.Days}}D{{end}}` +
+			`{{if .HasTimePart}}T{{end}}` +
+			`{{if .Hours}}{{.Hours}}H{{end}}` +
+			`{{if .Minutes}}{{.Minutes}}M{{end}}` +
+			`{{if .Seconds}}{{.Seconds}}S{{end}}`,
+	),
+)
+
+func legacyString(d *Duration) string {
+	// the template printed zero durations as P, which FromString rejects
+	if d.IsZero() {
+		return "PT0S"
+	}
+	var s bytes.Buffer
+	if err := legacyTmpl.Execute(&s, d); err != nil {
+		panic(err)
+	}
+	return s.String()
+}
+
+func TestStringMatchesLegacyTemplate(t *testing.T) {
+	t.Parallel()
+
+	values := []int{0, 1, 7, 12, 59, 1000, -1, -42}
+	for _, v := range values {
+		for field := 0; field < 7; field++ {
+			d := Duration{}
+			fields := []*int{&d.Years, &d.Months, &d.Weeks, &d.Days, &d.Hours, &d.Minutes, &d.Seconds}
+			*fields[field] = v
+			assertLegacyString(t, &d)
+
+			// fill every field up to this one as well
+			for i := 0; i <= field; i++ {
+				*fields[i] = v + i
+			}
+			assertLegacyString(t, &d)
+		}
+	}
+
+	d := Duration{Years: 1, Months: 2, Weeks: 3, Days: 4, Hours: 5, Minutes: 6, Seconds: 7}
+	assert.Equal(t, legacyString(&d), d.String())
+}
+
+// assertLegacyString checks String against the legacy template, which
+// only applies to durations with mixed signs if any component is
+// negative. Components which are all negative are folded into the sign.
+func assertLegacyString(t *testing.T, d *Duration) {
+	t.Helper()
+	if !d.hasNegative() || d.hasMixedSigns() {
+		assert.Equal(t, legacyString(d), d.String())
+		return
+	}
+	assert.Equal(t, "-"+legacyString(d.Abs()), d.String())
+}
+
+func BenchmarkString(b *testing.B) {
+	d := Duration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = d.String()
+	}
+}
+
+func BenchmarkStringLegacyTemplate(b *testing.B) {
+	d := Duration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = legacyString(&d)
+	}
+}
+
 func TestToEstimatedDuration(t *testing.T) {
 	t.Parallel()
 
@@ -119,3 +203,1593 @@ func TestDuration(t *testing.T) {
 
 	assert.Equal(t, stdDur, dur)
 }
+
+func TestDateTimePart(t *testing.T) {
+	t.Parallel()
+
+	d, err := FromString("P1Y2DT3H")
+	assert.Nil(t, err)
+
+	date := d.DatePart()
+	assert.Equal(t, "P1Y2D", date.String())
+	assert.True(t, date.HasDatePart())
+	assert.False(t, date.HasTimePart())
+
+	tm := d.TimePart()
+	assert.Equal(t, "PT3H", tm.String())
+	assert.False(t, tm.HasDatePart())
+	assert.True(t, tm.HasTimePart())
+
+	// combining both parts reproduces the original
+	combined := Duration{
+		Years:   date.Years,
+		Months:  date.Months,
+		Weeks:   date.Weeks,
+		Days:    date.Days,
+		Hours:   tm.Hours,
+		Minutes: tm.Minutes,
+		Seconds: tm.Seconds,
+	}
+	assert.Equal(t, *d, combined)
+
+	// the original is left untouched
+	assert.Equal(t, "P1Y2DT3H", d.String())
+}
+
+func TestSplitCombine(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		input    string
+		calendar string
+		exact    time.Duration
+	}{
+		{"P1M2DT3H30M", "P1M2D", 3*time.Hour + 30*time.Minute},
+		{"P1Y2M3W4DT5H6M7.5S", "P1Y2M3W4D", 5*time.Hour + 6*time.Minute + 7500*time.Millisecond},
+		{"P1Y", "P1Y", 0},
+		{"PT36H", "PT0S", 36 * time.Hour},
+		{"-P1DT2H", "-P1D", -2 * time.Hour},
+		{"-PT0.5S", "PT0S", -500 * time.Millisecond},
+	} {
+		d := MustFromString(tc.input)
+		calendar, exact := d.Split()
+		assert.Equal(t, tc.calendar, calendar.String(), tc.input)
+		assert.False(t, calendar.HasTimePart(), tc.input)
+		assert.Equal(t, tc.exact, exact, tc.input)
+
+		combined := Combine(calendar, exact)
+		assert.True(t, d.Equal(combined), "%s: %s", tc.input, combined)
+	}
+
+	// the time part is carried into hours
+	d := MustFromString("P1DT90M")
+	assert.Equal(t, "P1DT1H30M", Combine(d.Split()).String())
+
+	// mixed signs are kept
+	d = &Duration{Days: 1, Hours: -2}
+	calendar, exact := d.Split()
+	assert.Equal(t, &Duration{Days: 1}, calendar)
+	assert.Equal(t, -2*time.Hour, exact)
+	assert.Equal(t, d, Combine(calendar, exact))
+
+	calendar, exact = (*Duration)(nil).Split()
+	assert.Equal(t, &Duration{}, calendar)
+	assert.Equal(t, time.Duration(0), exact)
+	assert.Equal(t, "PT1H", Combine(nil, time.Hour).String())
+}
+
+func TestNegative(t *testing.T) {
+	t.Parallel()
+
+	dur, err := FromString("-P1DT2H")
+	assert.Nil(t, err)
+	assert.True(t, dur.Negative)
+	assert.Equal(t, 1, dur.Days)
+	assert.Equal(t, 2, dur.Hours)
+	assert.Equal(t, "-P1DT2H", dur.String())
+	assert.Equal(t, -(time.Hour * 26), dur.ToEstimatedDuration())
+
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, -(time.Hour * 26), dur.ToDuration(from))
+}
+
+func TestBetween(t *testing.T) {
+	t.Parallel()
+
+	// same instant
+	now := date(2021, 5, 5, 12, 0, 0)
+	assert.Equal(t, &Duration{}, Between(now, now))
+
+	// within a month
+	assert.Equal(t, &Duration{Days: 3, Hours: 4, Minutes: 5, Seconds: 6},
+		Between(date(2021, 5, 5, 12, 0, 0), date(2021, 5, 8, 16, 5, 6)))
+
+	// across a month boundary with a borrow on the time of day
+	assert.Equal(t, &Duration{Months: 2, Days: 2, Hours: 22},
+		Between(date(2021, 1, 15, 12, 0, 0), date(2021, 3, 18, 10, 0, 0)))
+
+	// across a month boundary with a borrow on the day
+	assert.Equal(t, &Duration{Days: 27},
+		Between(date(2021, 1, 20, 0, 0, 0), date(2021, 2, 16, 0, 0, 0)))
+
+	// from the end of a month into a shorter one
+	assert.Equal(t, &Duration{Days: 28},
+		Between(date(2021, 1, 31, 0, 0, 0), date(2021, 2, 28, 0, 0, 0)))
+
+	// across a year boundary
+	assert.Equal(t, &Duration{Months: 1, Days: 17, Hours: 1},
+		Between(date(2020, 12, 15, 23, 0, 0), date(2021, 2, 2, 0, 0, 0)))
+
+	// several years including a leap day
+	assert.Equal(t, &Duration{Years: 4, Months: 1},
+		Between(date(2016, 2, 29, 0, 0, 0), date(2020, 3, 29, 0, 0, 0)))
+
+	// sub-second differences
+	assert.Equal(t, &Duration{Seconds: 1, Nanoseconds: 250000000},
+		Between(date(2021, 5, 5, 12, 0, 0), date(2021, 5, 5, 12, 0, 1).Add(time.Millisecond*250)))
+
+	// a after b yields a negative duration
+	d := Between(date(2021, 3, 18, 10, 0, 0), date(2021, 1, 15, 12, 0, 0))
+	assert.True(t, d.Negative)
+	assert.Equal(t, "-P2M2DT22H", d.String())
+
+	// adding the result to a lands on b. This always holds if a is
+	// before b, but only for some pairs the other way round, like the
+	// last two, see Between and TestBetweenRoundTrip.
+	pairs := [][2]time.Time{
+		{date(2021, 1, 31, 0, 0, 0), date(2021, 3, 1, 0, 0, 0)},
+		{date(2020, 2, 29, 6, 0, 0), date(2021, 2, 28, 5, 0, 0)},
+		{date(2019, 12, 31, 23, 59, 59), date(2020, 1, 1, 0, 0, 0)},
+		{date(2021, 3, 31, 0, 0, 0), date(2021, 2, 28, 0, 0, 0)},
+		{date(2022, 7, 4, 8, 30, 0), date(2018, 11, 30, 17, 45, 15)},
+	}
+	for _, p := range pairs {
+		d := Between(p[0], p[1])
+		assert.Equal(t, p[1].Sub(p[0]), d.ToDuration(p[0]), "%s to %s: %s", p[0], p[1], d)
+	}
+}
+
+func TestFromStringDuplicateDesignators(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{
+		"P1Y1Y",
+		"PT1H1H",
+		"P1M2MT",
+		"P1M2M",
+		"PT1M2M",
+		"P1D1D",
+		"P1W2W",
+		"PT1S1S",
+		"P1DT1HT1H",
+		"PT1H1M1S1H",
+	} {
+		_, err := FromString(s)
+		assert.Equal(t, ErrBadFormat, err, s)
+	}
+
+	// M is months before T and minutes after
+	dur, err := FromString("P1MT2M")
+	assert.Nil(t, err)
+	assert.Equal(t, Duration{Months: 1, Minutes: 2}, *dur)
+}
+
+func TestFoldDaysIntoWeeks(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Days: 16}
+	assert.Equal(t, &Duration{Weeks: 2, Days: 2}, d.FoldDaysIntoWeeks())
+
+	// exact multiple
+	d = Duration{Days: 14}
+	assert.Equal(t, &Duration{Weeks: 2}, d.FoldDaysIntoWeeks())
+
+	// existing weeks and other components are kept
+	d = Duration{Months: 1, Weeks: 1, Days: 8, Hours: 3}
+	assert.Equal(t, &Duration{Months: 1, Weeks: 2, Days: 1, Hours: 3}, d.FoldDaysIntoWeeks())
+
+	// less than a week
+	d = Duration{Days: 6}
+	assert.Equal(t, &Duration{Days: 6}, d.FoldDaysIntoWeeks())
+
+	// the original is left untouched
+	assert.Equal(t, Duration{Days: 6}, d)
+}
+
+func TestExpandWeeksToDays(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Weeks: 2, Days: 2}
+	assert.Equal(t, &Duration{Days: 16}, d.ExpandWeeksToDays())
+
+	// exact multiple
+	d = Duration{Weeks: 3}
+	assert.Equal(t, &Duration{Days: 21}, d.ExpandWeeksToDays())
+
+	// both directions are inverse to each other
+	d = Duration{Years: 1, Weeks: 5, Days: 3, Minutes: 1}
+	assert.Equal(t, &d, d.ExpandWeeksToDays().FoldDaysIntoWeeks())
+	assert.Equal(t, d.ToEstimatedDuration(), d.ExpandWeeksToDays().ToEstimatedDuration())
+}
+
+func TestBetweenNegative(t *testing.T) {
+	t.Parallel()
+
+	pairs := [][2]time.Time{
+		{date(2021, 1, 15, 12, 0, 0), date(2021, 3, 18, 10, 0, 0)},
+		{date(2021, 1, 31, 0, 0, 0), date(2021, 3, 5, 0, 0, 0)},
+		{date(2021, 2, 28, 0, 0, 0), date(2021, 3, 31, 0, 0, 0)},
+		{date(2019, 12, 31, 23, 59, 59), date(2020, 1, 1, 0, 0, 0)},
+		{date(2018, 11, 30, 17, 45, 15), date(2022, 7, 4, 8, 30, 0)},
+	}
+	for _, p := range pairs {
+		earlier, later := p[0], p[1]
+
+		forward := Between(earlier, later)
+		backward := Between(later, earlier)
+
+		// the whole duration is negated, the components stay positive
+		assert.False(t, forward.Negative)
+		assert.True(t, backward.Negative)
+		assert.False(t, backward.hasMixedSigns())
+		for _, v := range backward.components() {
+			assert.GreaterOrEqual(t, v, 0)
+		}
+
+		assert.Equal(t, forward, backward.Abs())
+		assert.Equal(t, "-"+forward.String(), backward.String())
+	}
+}
+
+func TestFixedInterval(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Weeks: 1, Days: 1, Hours: 2, Minutes: 3, Seconds: 4}
+	interval, err := d.FixedInterval()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Hour*24*8+time.Hour*2+time.Minute*3+time.Second*4, interval)
+
+	d = Duration{Negative: true, Minutes: 5}
+	interval, err = d.FixedInterval()
+	assert.Nil(t, err)
+	assert.Equal(t, -time.Minute*5, interval)
+
+	d = Duration{Months: 1}
+	_, err = d.FixedInterval()
+	assert.Equal(t, ErrNotFixed, err)
+
+	d = Duration{Years: 1, Days: 1}
+	_, err = d.FixedInterval()
+	assert.Equal(t, ErrNotFixed, err)
+}
+
+func TestProgress(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	d := Duration{Months: 1}
+
+	// before start
+	assert.Equal(t, 0.0, d.Progress(start, start.Add(-time.Hour)))
+	assert.Equal(t, 0.0, d.Progress(start, start))
+
+	// February 2021 has 28 days
+	assert.InDelta(t, 0.5, d.Progress(start, start.AddDate(0, 0, 14)), 1e-9)
+	assert.InDelta(t, 0.25, d.Progress(start, start.AddDate(0, 0, 7)), 1e-9)
+
+	// after the end
+	assert.Equal(t, 1.0, d.Progress(start, start.AddDate(0, 1, 0)))
+	assert.Equal(t, 1.0, d.Progress(start, start.AddDate(1, 0, 0)))
+
+	// a zero duration is done as soon as it started
+	assert.Equal(t, 0.0, (&Duration{}).Progress(start, start.Add(-time.Second)))
+	assert.Equal(t, 1.0, (&Duration{}).Progress(start, start))
+}
+
+func TestNil(t *testing.T) {
+	t.Parallel()
+
+	var d *Duration
+	now := time.Now()
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, "<nil>", d.String())
+		assert.Equal(t, "<nil>", d.StringVerbose())
+		assert.Equal(t, "x=<nil>", string(d.AppendTo([]byte("x="))))
+		assert.Equal(t, "<nil>", d.WithOptions(FormatOptions{Weeks: WeeksOnly}).String())
+
+		assert.True(t, d.IsZero())
+		assert.False(t, d.HasTimePart())
+		assert.False(t, d.HasDatePart())
+		assert.Nil(t, d.DatePart())
+		assert.Nil(t, d.TimePart())
+		assert.Nil(t, d.FoldDaysIntoWeeks())
+		assert.Nil(t, d.ExpandWeeksToDays())
+		assert.Nil(t, d.Abs())
+		assert.Nil(t, d.Negate())
+		assert.Nil(t, d.Normalize())
+
+		// arithmetic and rounding start from the zero duration
+		assert.Equal(t, &Duration{Days: 1}, d.Add(&Duration{Days: 1}))
+		assert.Equal(t, &Duration{}, d.Mul(2))
+		assert.Equal(t, &Duration{}, d.Round(Hours, 1))
+		assert.Equal(t, &Duration{}, d.Truncate(Hours))
+		assert.Equal(t, &Duration{}, d.TopN(1))
+		calendar, _ := d.Split()
+		assert.Equal(t, &Duration{}, calendar)
+
+		assert.Equal(t, time.Duration(0), d.ToEstimatedDuration())
+		assert.Equal(t, time.Duration(0), d.ToDuration(now))
+		assert.True(t, now.Equal(d.AddTo(now)))
+		assert.Equal(t, 1.0, d.Progress(now, now))
+		interval, err := d.FixedInterval()
+		assert.Nil(t, err)
+		assert.Equal(t, time.Duration(0), interval)
+
+		// serialization treats nil as the zero duration
+		s, err := d.Format()
+		assert.Nil(t, err)
+		assert.Equal(t, "PT0S", s)
+		s, err = d.FormatAlternative()
+		assert.Nil(t, err)
+		assert.Equal(t, "P0000-00-00T00:00:00", s)
+		s, err = d.FormatAlternativeNormalized()
+		assert.Nil(t, err)
+		assert.Equal(t, "P0000-00-00T00:00:00", s)
+		assert.Equal(t, "0 seconds", d.Humanize())
+	})
+}
+
+func TestToGoString(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Days: 1, Hours: 2, Minutes: 3, Seconds: 4}
+	assert.Equal(t, "26h3m4s", d.ToGoString())
+
+	assert.Equal(t, "0s", (&Duration{}).ToGoString())
+	assert.Equal(t, "-1h30m0s", (&Duration{Negative: true, Hours: 1, Minutes: 30}).ToGoString())
+
+	// years are expanded using the estimated length without an anchor
+	d = Duration{Years: 1, Hours: 1}
+	assert.Equal(t, "8761h0m0s", d.ToGoString())
+
+	// and exactly with one; 2020 is a leap year
+	anchor := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "8785h0m0s", d.ToGoString(anchor))
+
+	for _, s := range []string{d.ToGoString(), d.ToGoString(anchor)} {
+		parsed, err := time.ParseDuration(s)
+		assert.Nil(t, err)
+		assert.Equal(t, s, parsed.String())
+	}
+	parsed, err := time.ParseDuration(d.ToGoString(anchor))
+	assert.Nil(t, err)
+	assert.Equal(t, d.ToDuration(anchor), parsed)
+}
+
+func TestDesignatorMeaning(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "years", DesignatorMeaning('Y', false))
+	assert.Equal(t, "months", DesignatorMeaning('M', false))
+	assert.Equal(t, "weeks", DesignatorMeaning('W', false))
+	assert.Equal(t, "days", DesignatorMeaning('D', false))
+	assert.Equal(t, "hours", DesignatorMeaning('H', true))
+	assert.Equal(t, "minutes", DesignatorMeaning('M', true))
+	assert.Equal(t, "seconds", DesignatorMeaning('S', true))
+
+	// designators on the wrong side of T
+	for _, c := range []byte("YWD") {
+		assert.Equal(t, "", DesignatorMeaning(c, true), string(c))
+	}
+	for _, c := range []byte("HS") {
+		assert.Equal(t, "", DesignatorMeaning(c, false), string(c))
+	}
+
+	// unknown designators
+	for _, c := range []byte("PTXm") {
+		assert.Equal(t, "", DesignatorMeaning(c, false), string(c))
+		assert.Equal(t, "", DesignatorMeaning(c, true), string(c))
+	}
+}
+
+func TestToDurationClamped(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	max := time.Hour * 24 * 30
+
+	// under max
+	d := Duration{Days: 2}
+	assert.Equal(t, time.Hour*48, d.ToDurationClamped(from, max))
+
+	// exceeding max
+	d = Duration{Months: 2}
+	assert.Equal(t, max, d.ToDurationClamped(from, max))
+
+	d = Duration{Negative: true, Months: 2}
+	assert.Equal(t, -max, d.ToDurationClamped(from, max))
+
+	// far beyond what time.Duration can hold
+	d = Duration{Years: 9999}
+	assert.Equal(t, max, d.ToDurationClamped(from, max))
+	assert.Equal(t, time.Duration(math.MaxInt64), d.ToDurationClamped(from, math.MaxInt64))
+
+	// the time part saturates as well
+	for _, d := range []Duration{
+		{Hours: 3000000},
+		{Seconds: 200000000000},
+		{Minutes: math.MaxInt64},
+		{Hours: math.MaxInt64, Seconds: math.MaxInt64, Nanoseconds: 999999999},
+		{Days: 1, Hours: 2562047, Minutes: 2562047 * 60},
+	} {
+		assert.Equal(t, time.Hour, d.ToDurationClamped(from, time.Hour), "%+v", d)
+		assert.Equal(t, time.Duration(math.MaxInt64), d.ToDuration(from), "%+v", d)
+		d.Negative = true
+		assert.Equal(t, -time.Hour, d.ToDurationClamped(from, time.Hour), "%+v", d)
+		assert.True(t, d.SubtractFrom(from).After(from), "%+v", d)
+	}
+
+	// sums within range are exact even if a component alone is not
+	d = Duration{Hours: 2562048, Minutes: -60}
+	assert.Equal(t, 2562047*time.Hour, d.ToDuration(from))
+	d = Duration{Hours: 1 << 40, Seconds: -(1 << 40) * 3600, Nanoseconds: 1}
+	assert.Equal(t, time.Nanosecond, d.ToDuration(from))
+}
+
+func TestFromStringFraction(t *testing.T) {
+	t.Parallel()
+
+	dur, err := FromString("PT1.5S")
+	assert.Nil(t, err)
+	assert.Equal(t, Duration{Seconds: 1, Nanoseconds: 500000000}, *dur)
+
+	// comma as decimal separator
+	dur, err = FromString("PT1,25S")
+	assert.Nil(t, err)
+	assert.Equal(t, Duration{Seconds: 1, Nanoseconds: 250000000}, *dur)
+
+	dur, err = FromString("P1DT0.000000001S")
+	assert.Nil(t, err)
+	assert.Equal(t, Duration{Days: 1, Nanoseconds: 1}, *dur)
+
+	// digits beyond nanoseconds are truncated
+	dur, err = FromString("PT0.1234567899S")
+	assert.Nil(t, err)
+	assert.Equal(t, 123456789, dur.Nanoseconds)
+
+	assert.Equal(t, time.Millisecond*1500, (&Duration{Seconds: 1, Nanoseconds: 5e8}).ToEstimatedDuration())
+	assert.Equal(t, -time.Millisecond*1500, (&Duration{Negative: true, Seconds: 1, Nanoseconds: 5e8}).ToEstimatedDuration())
+
+	// fractions are only allowed on the seconds
+	for _, s := range []string{"PT1.5M", "P1.5D", "PT.5S", "PT1.S", "PT1.5"} {
+		_, err = FromString(s)
+		assert.Equal(t, ErrBadFormat, err, s)
+	}
+}
+
+func TestIsCalendarOnly(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, (&Duration{Days: 1}).IsCalendarOnly())
+	assert.True(t, (&Duration{Years: 1, Weeks: 2}).IsCalendarOnly())
+	assert.False(t, (&Duration{Days: 1, Hours: 1}).IsCalendarOnly())
+	assert.False(t, (&Duration{Days: 1, Nanoseconds: 1}).IsCalendarOnly())
+	assert.False(t, (&Duration{Minutes: 1}).IsCalendarOnly())
+	assert.False(t, (&Duration{}).IsCalendarOnly())
+}
+
+func TestFromStringRFC3339(t *testing.T) {
+	t.Parallel()
+
+	accepted := map[string]Duration{
+		"P1Y":            {Years: 1},
+		"P1Y2M":          {Years: 1, Months: 2},
+		"P1Y2M3D":        {Years: 1, Months: 2, Days: 3},
+		"P2M3D":          {Months: 2, Days: 3},
+		"P3D":            {Days: 3},
+		"P3DT4H":         {Days: 3, Hours: 4},
+		"P1Y2M3DT4H5M6S": {Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6},
+		"PT4H":           {Hours: 4},
+		"PT4H5M":         {Hours: 4, Minutes: 5},
+		"PT5M6S":         {Minutes: 5, Seconds: 6},
+		"PT6S":           {Seconds: 6},
+		"PT0.5S":         {Nanoseconds: 500000000},
+		"PT1M1.25S":      {Minutes: 1, Seconds: 1, Nanoseconds: 250000000},
+	}
+	for s, want := range accepted {
+		d, err := FromStringRFC3339(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, &want, d, s)
+	}
+
+	rejected := []string{
+		// weeks
+		"P1W",
+		"P1W2D",
+		"P1Y2W",
+		"PT1H2W",
+		// skipped units
+		"P1Y3D",
+		"PT1H6S",
+		// trailing T
+		"P1DT",
+		// signs and prefixes
+		"-P1D",
+		"+P1D",
+		"xP1D",
+		// fractions on other units and commas
+		"PT1.5H",
+		"PT1,5S",
+	}
+	for _, s := range rejected {
+		_, err := FromStringRFC3339(s)
+		assert.Equal(t, ErrBadFormat, err, s)
+	}
+
+	for _, s := range []string{"", "P", "PT"} {
+		_, err := FromStringRFC3339(s)
+		assert.Equal(t, ErrEmpty, err, s)
+	}
+}
+
+func TestFromStringEmpty(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "P", "PT", "-P", "-PT"} {
+		d, err := FromString(s)
+		assert.Nil(t, d)
+		assert.True(t, errors.Is(err, ErrEmpty), s)
+		assert.False(t, errors.Is(err, ErrBadFormat), s)
+	}
+
+	for _, s := range []string{"asdf", "T", "P1", "PT1", "-", "1D"} {
+		d, err := FromString(s)
+		assert.Nil(t, d)
+		assert.True(t, errors.Is(err, ErrBadFormat), s)
+		assert.False(t, errors.Is(err, ErrEmpty), s)
+	}
+
+	// wrapped errors keep their class
+	_, err := ParseMany("P1D,P", ",")
+	assert.True(t, errors.Is(err, ErrEmpty))
+}
+
+func TestFromTimeDuration(t *testing.T) {
+	t.Parallel()
+
+	d := FromTimeDuration(time.Hour*26 + time.Minute*3 + time.Second*4)
+	assert.Equal(t, &Duration{Hours: 26, Minutes: 3, Seconds: 4}, d)
+	assert.Equal(t, "PT26H3M4S", d.String())
+
+	d = FromTimeDuration(time.Millisecond * 1500)
+	assert.Equal(t, &Duration{Seconds: 1, Nanoseconds: 500000000}, d)
+	assert.Equal(t, "PT1.5S", d.String())
+
+	d = FromTimeDuration(-time.Minute * 90)
+	assert.Equal(t, &Duration{Negative: true, Hours: 1, Minutes: 30}, d)
+	assert.Equal(t, "-PT1H30M", d.String())
+
+	assert.Equal(t, &Duration{}, FromTimeDuration(0))
+
+	for _, td := range []time.Duration{
+		0,
+		1,
+		-1,
+		time.Hour * 24 * 400,
+		-time.Second*59 - time.Nanosecond*999999999,
+		math.MaxInt64,
+		math.MinInt64,
+	} {
+		assert.Equal(t, td, FromTimeDuration(td).ToEstimatedDuration(), td.String())
+	}
+}
+
+func TestFromTimeDurationOpts(t *testing.T) {
+	t.Parallel()
+
+	day := time.Hour * 24
+	split := SplitOptions{Days: true, Weeks: true}
+
+	// the default keeps everything in hours
+	assert.Equal(t, "PT193H", FromTimeDurationOpts(time.Hour*193, SplitOptions{}).String())
+
+	assert.Equal(t, "P1W1DT1H", FromTimeDurationOpts(time.Hour*193, split).String())
+	assert.Equal(t, "P8DT1H", FromTimeDurationOpts(time.Hour*193, SplitOptions{Days: true}).String())
+	assert.Equal(t, "P1W1DT1H", FromTimeDurationOpts(time.Hour*193, SplitOptions{Weeks: true}).String())
+
+	// boundaries
+	assert.Equal(t, "PT23H59M59S", FromTimeDurationOpts(day-time.Second, split).String())
+	assert.Equal(t, "P1D", FromTimeDurationOpts(day, split).String())
+	assert.Equal(t, "P1DT1S", FromTimeDurationOpts(day+time.Second, split).String())
+	assert.Equal(t, "P6DT23H", FromTimeDurationOpts(day*7-time.Hour, split).String())
+	assert.Equal(t, "P1W", FromTimeDurationOpts(day*7, split).String())
+	assert.Equal(t, "P7D", FromTimeDurationOpts(day*7, SplitOptions{Days: true}).String())
+
+	// negative durations split symmetrically
+	assert.Equal(t, "-P1W1DT1H", FromTimeDurationOpts(-time.Hour*193, split).String())
+	assert.Equal(t, "-P1DT1S", FromTimeDurationOpts(-day-time.Second, split).String())
+
+	for _, td := range []time.Duration{day + time.Second, -time.Hour * 193, math.MaxInt64, math.MinInt64} {
+		assert.Equal(t, td, FromTimeDurationOpts(td, split).ToEstimatedDuration())
+	}
+}
+
+func TestTotalWeeks(t *testing.T) {
+	t.Parallel()
+
+	for s, weeks := range map[string]float64{
+		"PT0S":       0,
+		"P1W":        1,
+		"P14D":       2,
+		"P1W7D":      2,
+		"P1W3DT12H":  1.5,
+		"P3DT12H":    0.5,
+		"PT84H":      0.5,
+		"P1Y2M1W":    1,
+		"-P1W3DT12H": -1.5,
+	} {
+		d, err := FromString(s)
+		assert.NoError(t, err)
+		assert.Equal(t, weeks, d.TotalWeeks(), s)
+	}
+
+	assert.InDelta(t, 1.0/7/24, (&Duration{Hours: 1}).TotalWeeks(), 1e-12)
+	assert.Equal(t, -1.0, (&Duration{Days: -7}).TotalWeeks())
+	assert.Zero(t, (*Duration)(nil).TotalWeeks())
+}
+
+// date returns the time on the given day in UTC
+func date(year int, month time.Month, day, hour, min, sec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+}
+
+// loadLocation loads a location from the time zone database and skips
+// the test if it is not available
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skip(err)
+	}
+	return loc
+}
+
+func TestBetweenRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	berlin := loadLocation(t, "Europe/Berlin")
+	newYork := loadLocation(t, "America/New_York")
+
+	pairs := []struct {
+		name string
+		a, b time.Time
+	}{
+		{"end of month into february", date(2021, 1, 31, 0, 0, 0), date(2021, 2, 28, 0, 0, 0)},
+		{"end of month into leap february", date(2020, 1, 31, 0, 0, 0), date(2020, 2, 29, 0, 0, 0)},
+		{"end of month past february", date(2021, 1, 31, 0, 0, 0), date(2021, 3, 1, 0, 0, 0)},
+		{"march 31 to april 30", date(2021, 3, 31, 12, 0, 0), date(2021, 4, 30, 11, 0, 0)},
+		{"leap day to leap day", date(2016, 2, 29, 0, 0, 0), date(2020, 2, 29, 0, 0, 0)},
+		{"leap day to non-leap year", date(2020, 2, 29, 6, 0, 0), date(2021, 2, 28, 5, 0, 0)},
+		{"one second over new year", date(2019, 12, 31, 23, 59, 59), date(2020, 1, 1, 0, 0, 0)},
+		{"decades", date(1970, 1, 1, 0, 0, 0), date(2038, 1, 19, 3, 14, 7)},
+		{"sub-second", date(2021, 5, 5, 12, 0, 0), date(2021, 5, 5, 12, 0, 0).Add(time.Nanosecond)},
+		{"across spring forward", time.Date(2021, 3, 27, 12, 0, 0, 0, berlin), time.Date(2021, 3, 29, 12, 0, 0, 0, berlin)},
+		{"into the skipped hour", time.Date(2021, 3, 27, 2, 30, 0, 0, berlin), time.Date(2021, 3, 28, 3, 30, 0, 0, berlin)},
+		{"across fall back", time.Date(2021, 10, 30, 12, 0, 0, 0, berlin), time.Date(2021, 11, 1, 0, 0, 0, 0, berlin)},
+		{"within the repeated hour", time.Date(2021, 10, 31, 2, 30, 0, 0, berlin), time.Date(2021, 10, 31, 2, 30, 0, 0, berlin).Add(time.Hour)},
+		{"month across spring forward", time.Date(2021, 2, 28, 2, 30, 0, 0, newYork), time.Date(2021, 3, 31, 2, 30, 0, 0, newYork)},
+		{"different locations", time.Date(2021, 3, 27, 23, 0, 0, 0, berlin), time.Date(2021, 3, 28, 23, 0, 0, 0, newYork)},
+	}
+	for _, p := range pairs {
+		d := Between(p.a, p.b)
+		assert.False(t, d.Negative, p.name)
+		assert.Equal(t, p.b.Sub(p.a), d.ToDuration(p.a), "%s: %s", p.name, d)
+		assert.True(t, d.AddTo(p.a).Equal(p.b), "%s: %s", p.name, d)
+
+		// reversed pairs are the same span marked as negative, measured
+		// in the location of b, which does not round-trip from b
+		r := Between(p.b, p.a)
+		assert.True(t, r.Negative, p.name)
+		assert.True(t, r.Abs().AddTo(p.a.In(p.b.Location())).Equal(p.b), "%s: %s", p.name, r)
+
+		// taking the components backwards does
+		n := FromTimeDuration(p.a.Sub(p.b)).NormalizeCalendar(p.b)
+		assert.Equal(t, p.a.Sub(p.b), n.ToDuration(p.b), "%s: %s", p.name, n)
+	}
+
+	// the limitation of negative results as documented
+	r := Between(date(2021, 3, 3, 0, 0, 0), date(2021, 1, 31, 0, 0, 0))
+	assert.Equal(t, "-P1M", r.String())
+	assert.Equal(t, date(2021, 2, 3, 0, 0, 0), r.AddTo(date(2021, 3, 3, 0, 0, 0)))
+
+	// wall-clock days are kept across DST transitions
+	assert.Equal(t, "P2D", Between(time.Date(2021, 3, 27, 12, 0, 0, 0, berlin), time.Date(2021, 3, 29, 12, 0, 0, 0, berlin)).String())
+	assert.Equal(t, "P1M", Between(time.Date(2021, 3, 1, 0, 0, 0, 0, newYork), time.Date(2021, 4, 1, 0, 0, 0, 0, newYork)).String())
+	assert.Equal(t, "P28D", Between(date(2021, 1, 31, 0, 0, 0), date(2021, 2, 28, 0, 0, 0)).String())
+}
+
+func TestAddToDST(t *testing.T) {
+	t.Parallel()
+
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	// 2021-03-28 is only 23 hours long in Berlin
+	start := time.Date(2021, 3, 27, 12, 0, 0, 0, berlin)
+	day := &Duration{Days: 1}
+
+	end := day.AddTo(start)
+	assert.Equal(t, time.Date(2021, 3, 28, 12, 0, 0, 0, berlin), end)
+	assert.Equal(t, berlin, end.Location())
+	assert.Equal(t, time.Hour*23, day.ToDuration(start))
+	assert.Equal(t, end, start.Add(day.ToDuration(start)))
+
+	// the estimate drifts by the DST offset
+	assert.Equal(t, time.Date(2021, 3, 28, 13, 0, 0, 0, berlin), start.Add(day.ToEstimatedDuration()))
+
+	// the time part is elapsed time, not wall-clock time
+	hours := &Duration{Hours: 24}
+	assert.Equal(t, time.Date(2021, 3, 28, 13, 0, 0, 0, berlin), hours.AddTo(start))
+
+	// falling back makes the day 25 hours long
+	start = time.Date(2021, 10, 30, 12, 0, 0, 0, berlin)
+	assert.Equal(t, time.Date(2021, 10, 31, 12, 0, 0, 0, berlin), day.AddTo(start))
+	assert.Equal(t, time.Hour*25, day.ToDuration(start))
+}
+
+func TestAsSecondsOnly(t *testing.T) {
+	t.Parallel()
+
+	for s, expected := range map[string]string{
+		"P1DT1H1M1S": "PT90061S",
+		"PT1.5S":     "PT1.5S",
+		"P1W":        "PT604800S",
+		"P1Y2M":      "PT36720000S",
+		"-P1DT0.25S": "-PT86400.25S",
+		"PT0S":       "PT0S",
+	} {
+		d, err := FromString(s)
+		assert.NoError(t, err)
+
+		secs := d.AsSecondsOnly()
+		assert.Equal(t, expected, secs.String(), s)
+		assert.Equal(t, d.ToEstimatedDuration(), secs.ToEstimatedDuration(), s)
+	}
+
+	// negative components are folded into the flag
+	d := &Duration{Minutes: -1, Seconds: -30}
+	assert.Equal(t, &Duration{Negative: true, Seconds: 90}, d.AsSecondsOnly())
+
+	// the fraction shares the sign of the seconds
+	d = &Duration{Seconds: 1, Nanoseconds: -5}
+	assert.Equal(t, &Duration{Nanoseconds: 999999995}, d.AsSecondsOnly())
+	assert.Equal(t, "PT0.999999995S", d.AsSecondsOnly().String())
+	d = &Duration{Negative: true, Minutes: 1, Seconds: -30, Nanoseconds: 5e8}
+	assert.Equal(t, &Duration{Negative: true, Seconds: 30, Nanoseconds: 5e8}, d.AsSecondsOnly())
+
+	assert.Nil(t, (*Duration)(nil).AsSecondsOnly())
+
+	// overflows are reported
+	for _, d := range []*Duration{
+		{Years: math.MaxInt / 1000},
+		{Days: math.MaxInt / 86400, Hours: 24},
+		{Negative: true, Seconds: math.MaxInt, Nanoseconds: 1e9},
+	} {
+		_, err := d.AsSecondsOnlyChecked()
+		assert.ErrorIs(t, err, ErrOutOfRange, "%+v", d)
+		assert.PanicsWithValue(t, ErrOutOfRange, func() { d.AsSecondsOnly() }, "%+v", d)
+	}
+}
+
+func TestAppliedTo(t *testing.T) {
+	t.Parallel()
+
+	utc := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	for _, tc := range []struct {
+		d        string
+		from     time.Time
+		expected AppliedOffsets
+	}{
+		{"P1M", utc(2023, time.January, 1), AppliedOffsets{Months: 1, MonthDays: 31, Days: 31, Elapsed: 31 * 24 * time.Hour}},
+		{"P1M", utc(2023, time.February, 1), AppliedOffsets{Months: 1, MonthDays: 28, Days: 28, Elapsed: 28 * 24 * time.Hour}},
+		{"P1M", utc(2024, time.February, 1), AppliedOffsets{Months: 1, MonthDays: 29, Days: 29, Elapsed: 29 * 24 * time.Hour}},
+		// days which do not exist roll over
+		{"P1M", utc(2023, time.January, 31), AppliedOffsets{Months: 1, MonthDays: 31, Days: 31, Elapsed: 31 * 24 * time.Hour}},
+		{"P1Y", utc(2024, time.January, 1), AppliedOffsets{Months: 12, MonthDays: 366, Days: 366, Elapsed: 366 * 24 * time.Hour}},
+		{"P1M1W2D", utc(2023, time.February, 1), AppliedOffsets{Months: 1, MonthDays: 28, Days: 37, Elapsed: 37 * 24 * time.Hour}},
+		// the time part is not counted in days
+		{"P1DT13H", utc(2023, time.February, 1), AppliedOffsets{Days: 1, Elapsed: 37 * time.Hour}},
+		{"-P1M", utc(2023, time.March, 1), AppliedOffsets{Months: -1, MonthDays: -28, Days: -28, Elapsed: -28 * 24 * time.Hour}},
+		{"PT0S", utc(2023, time.March, 1), AppliedOffsets{}},
+	} {
+		d := MustFromString(tc.d)
+		to, offsets := d.AppliedTo(tc.from)
+		assert.Equal(t, d.AddTo(tc.from), to, "%s from %s", tc.d, tc.from)
+		assert.Equal(t, tc.expected, offsets, "%s from %s", tc.d, tc.from)
+	}
+
+	// days are counted in the location of from
+	berlin := loadLocation(t, "Europe/Berlin")
+	from := time.Date(2021, 3, 1, 12, 0, 0, 0, berlin)
+	_, offsets := MustFromString("P1M").AppliedTo(from)
+	assert.Equal(t, AppliedOffsets{Months: 1, MonthDays: 31, Days: 31, Elapsed: 31*24*time.Hour - time.Hour}, offsets)
+}
+
+func TestSubtractFrom(t *testing.T) {
+	t.Parallel()
+
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	for _, tc := range []struct {
+		name     string
+		d        string
+		t        time.Time
+		expected time.Time
+	}{
+		{"days", "P90D", date(2021, 6, 30, 12, 0, 0), date(2021, 4, 1, 12, 0, 0)},
+		{"time part", "PT1H30M", date(2021, 1, 1, 1, 0, 0), date(2020, 12, 31, 23, 30, 0)},
+		{"month", "P1M", date(2021, 4, 30, 0, 0, 0), date(2021, 3, 30, 0, 0, 0)},
+		{"month rolls over", "P1M", date(2021, 3, 31, 0, 0, 0), date(2021, 3, 3, 0, 0, 0)},
+		{"month rolls over in leap year", "P1M", date(2020, 3, 31, 0, 0, 0), date(2020, 3, 2, 0, 0, 0)},
+		{"leap day", "P1Y", date(2020, 2, 29, 0, 0, 0), date(2019, 3, 1, 0, 0, 0)},
+		{"days before months", "P1M1D", date(2021, 3, 1, 0, 0, 0), date(2021, 1, 28, 0, 0, 0)},
+		{"negative", "-P1D", date(2021, 1, 1, 0, 0, 0), date(2021, 1, 2, 0, 0, 0)},
+		{"across spring forward", "P1D", time.Date(2021, 3, 28, 12, 0, 0, 0, berlin), time.Date(2021, 3, 27, 12, 0, 0, 0, berlin)},
+		{"hours across spring forward", "PT24H", time.Date(2021, 3, 28, 12, 0, 0, 0, berlin), time.Date(2021, 3, 27, 11, 0, 0, 0, berlin)},
+		{"across fall back", "P1D", time.Date(2021, 10, 31, 12, 0, 0, 0, berlin), time.Date(2021, 10, 30, 12, 0, 0, 0, berlin)},
+	} {
+		d, err := FromString(tc.d)
+		assert.NoError(t, err)
+
+		start := d.SubtractFrom(tc.t)
+		assert.True(t, tc.expected.Equal(start), "%s: %s", tc.name, start)
+		assert.Equal(t, tc.t.Location(), start.Location())
+	}
+
+	// SubtractFrom reverses AddTo as long as no day rolls over
+	for _, s := range []string{"P1Y2M3W4DT5H6M7.5S", "P1M1D", "-PT25H", "P1DT1H"} {
+		d, err := FromString(s)
+		assert.NoError(t, err)
+		now := time.Date(2021, 3, 27, 1, 30, 0, 0, berlin)
+		assert.True(t, now.Equal(d.SubtractFrom(d.AddTo(now))), s)
+	}
+
+	now := time.Now()
+	assert.True(t, now.Equal((*Duration)(nil).SubtractFrom(now)))
+}
+
+func TestFromStringNumericNoise(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{
+		"P1e2D",
+		"P1E2D",
+		"PT1e3S",
+		"PT1.5e3S",
+		"P+1D",
+		"P-1D",
+		"P--1D",
+		"P1_000D",
+		"P0x10D",
+		"P1 D",
+		"P 1D",
+		"P1,000D",
+		"P1.5D",
+		"PT.5S",
+		"PT1.S",
+		"P١D",
+		"PT1.5.5S",
+	} {
+		_, err := FromString(s)
+		assert.ErrorIs(t, err, ErrBadFormat, s)
+	}
+}
+
+func TestFromStringSignPrefix(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]*Duration{
+		"+P1D":     {Days: 1},
+		"-P1D":     {Negative: true, Days: 1},
+		"+PT1.5S":  {Seconds: 1, Nanoseconds: 5e8},
+		"+P1Y2W3D": {Years: 1, Weeks: 2, Days: 3},
+	} {
+		d, err := FromString(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, d, input)
+	}
+
+	for _, s := range []string{
+		"P+1D",
+		"P1Y+2D",
+		"PT+1H",
+		"++P1D",
+		"--P1D",
+		"-+P1D",
+		"+-P1D",
+		"+ P1D",
+		"P1D+",
+		"+",
+		"-",
+	} {
+		_, err := FromString(s)
+		assert.ErrorIs(t, err, ErrBadFormat, s)
+	}
+
+	for _, s := range []string{"+P", "+PT"} {
+		_, err := FromString(s)
+		assert.ErrorIs(t, err, ErrEmpty, s)
+	}
+
+	// the plus sign is not part of RFC 3339
+	_, err := FromStringRFC3339("+P1D")
+	assert.ErrorIs(t, err, ErrBadFormat)
+}
+
+func TestToDurationBefore(t *testing.T) {
+	t.Parallel()
+
+	day := time.Hour * 24
+	month := &Duration{Months: 1}
+
+	anchor := time.Date(2021, 3, 30, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, day*28, month.ToDurationBefore(anchor))
+	assert.Equal(t, day*31, month.ToDuration(anchor))
+
+	// February 30 rolls over into March 1 in leap years
+	anchor = time.Date(2020, 3, 30, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, day*29, month.ToDurationBefore(anchor))
+
+	anchor = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, day*28, month.ToDurationBefore(anchor))
+	assert.Equal(t, day*31, month.ToDuration(anchor))
+
+	// fixed durations are symmetric
+	d := &Duration{Days: 3, Hours: 4}
+	assert.Equal(t, d.ToDuration(anchor), d.ToDurationBefore(anchor))
+
+	// negative durations end in the future
+	assert.Equal(t, -day*31, (&Duration{Negative: true, Months: 1}).ToDurationBefore(anchor))
+
+	assert.Zero(t, (*Duration)(nil).ToDurationBefore(anchor))
+}
+
+func TestTotals(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"PT0S", "PT1.5S", "P1DT1H1M1S", "P1Y2M3W4DT5H6M7.123456789S", "-P1DT0.25S"} {
+		d, err := FromString(s)
+		assert.NoError(t, err)
+
+		est := d.ToEstimatedDuration()
+		assert.InDelta(t, est.Seconds(), d.TotalSeconds(), 1e-6, s)
+		assert.InDelta(t, est.Minutes(), d.TotalMinutes(), 1e-6, s)
+		assert.InDelta(t, est.Hours(), d.TotalHours(), 1e-6, s)
+	}
+
+	d := &Duration{Hours: 1, Minutes: 30}
+	assert.Equal(t, 5400.0, d.TotalSeconds())
+	assert.Equal(t, 90.0, d.TotalMinutes())
+	assert.Equal(t, 1.5, d.TotalHours())
+
+	// all negative components are as negative as the flag
+	assert.Equal(t, -5400.0, (&Duration{Hours: -1, Minutes: -30}).TotalSeconds())
+	assert.Equal(t, -5400.0, (&Duration{Negative: true, Hours: 1, Minutes: 30}).TotalSeconds())
+
+	// beyond the range of time.Duration
+	assert.Equal(t, 1000*365*86400.0, (&Duration{Years: 1000}).TotalSeconds())
+
+	// exact totals depend on the start
+	month := &Duration{Months: 1}
+	assert.Equal(t, 28*86400.0, month.TotalSecondsAt(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 31*86400.0, month.TotalSecondsAt(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)))
+
+	assert.Zero(t, (*Duration)(nil).TotalSeconds())
+	assert.Zero(t, (*Duration)(nil).TotalHours())
+}
+
+func TestTotalsAllocs(t *testing.T) {
+	d := &Duration{Negative: true, Years: 1, Days: 2, Seconds: 3, Nanoseconds: 4}
+	from := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = d.TotalSeconds()
+		_ = d.TotalHours()
+		_ = d.TotalSecondsAt(from)
+	}))
+}
+
+func TestFitsInTimeDuration(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"PT0S", "P1Y2M3DT4H5M6.7S", "P292Y", "-P292Y", "PT2562047H47M16.854775807S", "-PT2562047H47M16.854775808S"} {
+		d := MustFromString(s)
+		assert.True(t, d.FitsInTimeDuration(), s)
+	}
+	for _, s := range []string{"P99999Y", "-P99999Y", "P293Y", "PT2562047H47M16.854775808S", "P1YT2562047H"} {
+		d := MustFromString(s)
+		assert.False(t, d.FitsInTimeDuration(), s)
+	}
+
+	// components of different signs may cancel out
+	d := &Duration{Years: 1000, Days: -365 * 1000}
+	assert.True(t, d.FitsInTimeDuration())
+	assert.True(t, (*Duration)(nil).FitsInTimeDuration())
+}
+
+func TestTotalMonths(t *testing.T) {
+	t.Parallel()
+
+	for s, months := range map[string]int64{
+		"P1Y2M":     14,
+		"P2M":       2,
+		"P3Y":       36,
+		"-P1Y2M":    -14,
+		"P1Y2M3D":   14,
+		"P1MT12H":   1,
+		"P1W":       0,
+		"P40M":      40,
+		"P1Y1M1W1D": 13,
+	} {
+		d, err := FromString(s)
+		assert.NoError(t, err)
+		assert.Equal(t, months, d.TotalMonths(), s)
+
+		strict, err := d.TotalMonthsStrict()
+		if d.IsCalendarOnly() && d.Weeks == 0 && d.Days == 0 {
+			assert.NoError(t, err, s)
+			assert.Equal(t, months, strict, s)
+		} else {
+			assert.ErrorIs(t, err, ErrNotWholeMonths, s)
+		}
+	}
+
+	// all negative components
+	assert.Equal(t, int64(-14), (&Duration{Years: -1, Months: -2}).TotalMonths())
+
+	// overflows saturate or fail
+	d := &Duration{Years: math.MaxInt64 / 12, Months: 12}
+	assert.Equal(t, int64(math.MaxInt64), d.TotalMonths())
+	_, err := d.TotalMonthsStrict()
+	assert.ErrorIs(t, err, ErrOutOfRange)
+
+	d = &Duration{Negative: true, Years: math.MaxInt}
+	assert.Equal(t, int64(math.MinInt64), d.TotalMonths())
+
+	d = &Duration{Years: math.MaxInt64 / 12, Months: 7}
+	assert.Equal(t, int64(math.MaxInt64), d.TotalMonths())
+
+	assert.Zero(t, (*Duration)(nil).TotalMonths())
+	months, err := (*Duration)(nil).TotalMonthsStrict()
+	assert.NoError(t, err)
+	assert.Zero(t, months)
+}
+
+func TestTotalDaysEstimate(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 30.5, (&Duration{Months: 1, Hours: 12}).TotalDaysEstimate())
+	assert.Equal(t, 372.0, (&Duration{Years: 1, Weeks: 1}).TotalDaysEstimate())
+	assert.Equal(t, -1.5, (&Duration{Negative: true, Days: 1, Hours: 12}).TotalDaysEstimate())
+	assert.Zero(t, (*Duration)(nil).TotalDaysEstimate())
+}
+
+func TestFromStringLimited(t *testing.T) {
+	t.Parallel()
+
+	d, err := FromStringLimited("P1DT2H", 64)
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{Days: 1, Hours: 2}, d)
+
+	// the limit is inclusive
+	d, err = FromStringLimited("P1DT2H", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{Days: 1, Hours: 2}, d)
+
+	d, err = FromStringLimited("P1DT2H", 5)
+	assert.Nil(t, d)
+	assert.ErrorIs(t, err, ErrTooLong)
+
+	d, err = FromStringLimited("P"+strings.Repeat("1", 1<<20)+"D", 64)
+	assert.Nil(t, d)
+	assert.ErrorIs(t, err, ErrTooLong)
+
+	// other errors are passed through
+	_, err = FromStringLimited("asdf", 64)
+	assert.ErrorIs(t, err, ErrBadFormat)
+	_, err = FromStringLimited("", 0)
+	assert.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestFromStringFlexible(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]*Duration{
+		"30":        {Seconds: 30},
+		"0":         {},
+		"3600":      {Seconds: 3600},
+		"-30":       {Negative: true, Seconds: 30},
+		"PT30S":     {Seconds: 30},
+		"P1DT2H":    {Days: 1, Hours: 2},
+		"-PT1.5S":   {Negative: true, Seconds: 1, Nanoseconds: 5e8},
+		"P0030D":    {Days: 30},
+		"PT000030S": {Seconds: 30},
+	} {
+		d, err := FromStringFlexible(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, d, input)
+	}
+
+	for input, expected := range map[string]error{
+		"":                     ErrEmpty,
+		"P":                    ErrEmpty,
+		"30s":                  ErrBadFormat,
+		"1.5":                  ErrBadFormat,
+		" 30":                  ErrBadFormat,
+		"30S":                  ErrBadFormat,
+		"-9223372036854775808": ErrOutOfRange,
+		"99999999999999999999": ErrBadFormat,
+	} {
+		d, err := FromStringFlexible(input)
+		assert.Nil(t, d, input)
+		assert.ErrorIs(t, err, expected, input)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	t.Parallel()
+
+	d := &Duration{}
+	units := []string{"years", "months", "weeks", "days", "hours", "minutes", "seconds", "nanoseconds"}
+	for i, unit := range units {
+		assert.NoError(t, d.Set(unit, i+1))
+	}
+	assert.Equal(t, &Duration{Years: 1, Months: 2, Weeks: 3, Days: 4, Hours: 5, Minutes: 6, Seconds: 7, Nanoseconds: 8}, d)
+
+	for i, unit := range units {
+		v, err := d.Get(unit)
+		assert.NoError(t, err)
+		assert.Equal(t, i+1, v, unit)
+	}
+
+	// the units are the ones returned by DesignatorMeaning
+	for _, designator := range []byte("YMWD") {
+		_, err := d.Get(DesignatorMeaning(designator, false))
+		assert.NoError(t, err)
+	}
+	for _, designator := range []byte("HMS") {
+		_, err := d.Get(DesignatorMeaning(designator, true))
+		assert.NoError(t, err)
+	}
+
+	for _, unit := range []string{"", "year", "Years", "millis", "negative"} {
+		_, err := d.Get(unit)
+		assert.ErrorIs(t, err, ErrUnknownUnit, unit)
+		assert.ErrorIs(t, d.Set(unit, 1), ErrUnknownUnit, unit)
+	}
+
+	v, err := (*Duration)(nil).Get("days")
+	assert.NoError(t, err)
+	assert.Zero(t, v)
+}
+
+func TestFindString(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		s       string
+		match   string
+		expects *Duration
+	}{
+		{"backoff=PT30S retry", "PT30S", &Duration{Seconds: 30}},
+		{"P1D", "P1D", &Duration{Days: 1}},
+		{"retry in P1DT2H, then give up", "P1DT2H", &Duration{Days: 1, Hours: 2}},
+		{"offset -PT1.5S applied", "-PT1.5S", &Duration{Negative: true, Seconds: 1, Nanoseconds: 500000000}},
+		{"Please wait P2W", "P2W", &Duration{Weeks: 2}},
+		{"PT no P nor XP1D but (P3M)", "P3M", &Duration{Months: 3}},
+		{"first P1Y then P2Y", "P1Y", &Duration{Years: 1}},
+		{"ttl:\"PT5M\"", "PT5M", &Duration{Minutes: 5}},
+	} {
+		d, m, err := FindString(tc.s)
+		assert.NoError(t, err, tc.s)
+		assert.Equal(t, tc.match, m, tc.s)
+		assert.Equal(t, tc.expects, d, tc.s)
+	}
+
+	for _, s := range []string{"", "nothing here", "Please", "P", "PT", "XP1D", "P1Dx", "P1D2", "PT1HM"} {
+		d, m, err := FindString(s)
+		assert.Nil(t, d, s)
+		assert.Empty(t, m, s)
+		assert.ErrorIs(t, err, ErrBadFormat, s)
+	}
+}
+
+func TestToDurationIn(t *testing.T) {
+	t.Parallel()
+
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	day := &Duration{Days: 1}
+
+	// noon in Berlin on the day before DST starts
+	from := time.Date(2021, 3, 27, 11, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Hour*24, day.ToDuration(from))
+	assert.Equal(t, time.Hour*24, day.ToDurationIn(from, time.UTC))
+	assert.Equal(t, time.Hour*23, day.ToDurationIn(from, berlin))
+
+	// the location of from does not matter
+	assert.Equal(t, time.Hour*24, day.ToDurationIn(from.In(berlin), time.UTC))
+	assert.Equal(t, time.Hour*23, day.ToDurationIn(from.In(berlin), berlin))
+
+	// and DST ends
+	from = time.Date(2021, 10, 30, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Hour*24, day.ToDurationIn(from, time.UTC))
+	assert.Equal(t, time.Hour*25, day.ToDurationIn(from, berlin))
+
+	// the time part is not affected
+	hours := &Duration{Hours: 24}
+	assert.Equal(t, time.Hour*24, hours.ToDurationIn(from, berlin))
+}
+
+func TestToDurationInDST(t *testing.T) {
+	t.Parallel()
+
+	berlin := loadLocation(t, "Europe/Berlin")
+	newYork := loadLocation(t, "America/New_York")
+
+	for _, tc := range []struct {
+		loc      *time.Location
+		from     time.Time
+		d        string
+		expected time.Duration
+		wall     string
+	}{
+		// Berlin springs forward on 2021-03-28 at 02:00
+		{berlin, time.Date(2021, 3, 27, 12, 0, 0, 0, berlin), "P1D", 23 * time.Hour, "2021-03-28 12:00"},
+		{berlin, time.Date(2021, 3, 27, 12, 0, 0, 0, berlin), "PT24H", 24 * time.Hour, "2021-03-28 13:00"},
+		{berlin, time.Date(2021, 3, 27, 12, 0, 0, 0, berlin), "P1DT1H", 24 * time.Hour, "2021-03-28 13:00"},
+		{berlin, time.Date(2021, 3, 28, 0, 0, 0, 0, berlin), "P1D", 23 * time.Hour, "2021-03-29 00:00"},
+		{berlin, time.Date(2021, 3, 22, 12, 0, 0, 0, berlin), "P1W", 7*24*time.Hour - time.Hour, "2021-03-29 12:00"},
+		{berlin, time.Date(2021, 3, 1, 12, 0, 0, 0, berlin), "P1M", 31*24*time.Hour - time.Hour, "2021-04-01 12:00"},
+		// and falls back on 2021-10-31 at 03:00
+		{berlin, time.Date(2021, 10, 30, 12, 0, 0, 0, berlin), "P1D", 25 * time.Hour, "2021-10-31 12:00"},
+		{berlin, time.Date(2021, 10, 30, 12, 0, 0, 0, berlin), "PT24H", 24 * time.Hour, "2021-10-31 11:00"},
+		{berlin, time.Date(2021, 10, 31, 0, 0, 0, 0, berlin), "P1D", 25 * time.Hour, "2021-11-01 00:00"},
+		{berlin, time.Date(2021, 10, 25, 12, 0, 0, 0, berlin), "P1W", 7*24*time.Hour + time.Hour, "2021-11-01 12:00"},
+		// New York springs forward on 2021-03-14 at 02:00
+		{newYork, time.Date(2021, 3, 13, 12, 0, 0, 0, newYork), "P1D", 23 * time.Hour, "2021-03-14 12:00"},
+		{newYork, time.Date(2021, 3, 13, 12, 0, 0, 0, newYork), "PT24H", 24 * time.Hour, "2021-03-14 13:00"},
+		{newYork, time.Date(2021, 3, 14, 0, 0, 0, 0, newYork), "P1D", 23 * time.Hour, "2021-03-15 00:00"},
+		// and falls back on 2021-11-07 at 02:00
+		{newYork, time.Date(2021, 11, 6, 12, 0, 0, 0, newYork), "P1D", 25 * time.Hour, "2021-11-07 12:00"},
+		{newYork, time.Date(2021, 11, 6, 12, 0, 0, 0, newYork), "PT24H", 24 * time.Hour, "2021-11-07 11:00"},
+		{newYork, time.Date(2021, 11, 7, 0, 0, 0, 0, newYork), "P1D", 25 * time.Hour, "2021-11-08 00:00"},
+		// days without a transition are 24 hours long
+		{newYork, time.Date(2021, 7, 1, 12, 0, 0, 0, newYork), "P1D", 24 * time.Hour, "2021-07-02 12:00"},
+	} {
+		d := MustFromString(tc.d)
+		name := fmt.Sprintf("%s from %s", tc.d, tc.from)
+
+		// the location of from does not matter
+		for _, from := range []time.Time{tc.from, tc.from.UTC(), tc.from.In(newYork)} {
+			assert.Equal(t, tc.expected, d.ToDurationIn(from, tc.loc), name)
+			end := from.Add(d.ToDurationIn(from, tc.loc)).In(tc.loc)
+			assert.Equal(t, tc.wall, end.Format("2006-01-02 15:04"), name)
+		}
+
+		// in UTC, every day is 24 hours long
+		if fixed, err := d.FixedInterval(); err == nil {
+			assert.Equal(t, fixed, d.ToDurationIn(tc.from, time.UTC), name)
+		}
+	}
+}
+
+func TestMustFromString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, &Duration{Days: 1, Hours: 2}, MustFromString("P1DT2H"))
+	assert.Equal(t, &Duration{}, MustFromString("P0D"))
+	assert.Equal(t, &Duration{Negative: true, Weeks: 2}, MustFromString("-P2W"))
+
+	assert.PanicsWithValue(t, `iso8601duration: FromString("asdf"): bad format string`, func() { MustFromString("asdf") })
+	assert.PanicsWithValue(t, `iso8601duration: FromString("P"): empty duration`, func() { MustFromString("P") })
+	assert.Panics(t, func() { MustFromString("") })
+}
+
+func TestToEstimatedDurationRounded(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d        string
+		unit     time.Duration
+		expected time.Duration
+	}{
+		{"PT1.5S", time.Second, time.Second * 2},
+		{"PT1.4S", time.Second, time.Second},
+		{"PT2.5S", time.Second, time.Second * 3},
+		{"-PT1.5S", time.Second, -time.Second * 2},
+		{"PT1M29.999S", time.Minute, time.Minute},
+		{"PT1M30S", time.Minute, time.Minute * 2},
+		{"PT1.2345S", time.Millisecond, time.Millisecond * 1235},
+		{"P1DT12H", time.Hour * 24, time.Hour * 48},
+		{"P1DT11H", time.Hour * 24, time.Hour * 24},
+		{"PT1.5S", 0, time.Millisecond * 1500},
+		{"PT1.5S", -time.Second, time.Millisecond * 1500},
+	} {
+		d, err := FromString(tc.d)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, d.ToEstimatedDurationRounded(tc.unit), "%s to %s", tc.d, tc.unit)
+		assert.Equal(t, tc.expected, d.Normalize().ToEstimatedDurationRounded(tc.unit), "%s to %s", tc.d, tc.unit)
+	}
+
+	assert.Zero(t, (*Duration)(nil).ToEstimatedDurationRounded(time.Second))
+}
+
+func TestFromStringWithInfo(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"P1Y2M3DT4H5M6S", "P2W", "PT0S", "P0D", "PT1,5S", "PT1.05S", "P10D", "PT0.5S"} {
+		d, info, err := FromStringWithInfo(s)
+		assert.NoError(t, err)
+		assert.Equal(t, ParseInfo{StrictlyConformant: true}, info, s)
+
+		expected, err := FromString(s)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, d)
+	}
+
+	for s, expected := range map[string]ParseInfo{
+		"-P1D":    {Signed: true},
+		"+P1D":    {Signed: true},
+		"P1W2D":   {MixedWeeks: true},
+		"P1Y1W":   {MixedWeeks: true},
+		"P01D":    {LeadingZeros: true},
+		"PT00S":   {LeadingZeros: true},
+		"-P1W01D": {Signed: true, MixedWeeks: true, LeadingZeros: true},
+	} {
+		_, info, err := FromStringWithInfo(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, info, s)
+		assert.False(t, info.StrictlyConformant, s)
+	}
+
+	_, info, err := FromStringWithInfo("P")
+	assert.ErrorIs(t, err, ErrEmpty)
+	assert.Equal(t, ParseInfo{}, info)
+
+	// T should be omitted without time components, but is accepted
+	for s, expected := range map[string]*Duration{
+		"P1DT":    {Days: 1},
+		"P1YT":    {Years: 1},
+		"-P1W2DT": {Negative: true, Weeks: 1, Days: 2},
+		"P0DT":    {},
+	} {
+		d, info, err := FromStringWithInfo(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, d, s)
+		assert.True(t, info.EmptyTimePart, s)
+		assert.False(t, info.StrictlyConformant, s)
+	}
+	_, info, err = FromStringWithInfo("P1DT1H")
+	assert.NoError(t, err)
+	assert.False(t, info.EmptyTimePart)
+	assert.True(t, info.StrictlyConformant)
+
+	d, m, err := FindString("wait P1DT then P2D")
+	assert.NoError(t, err)
+	assert.Equal(t, "P1DT", m)
+	assert.Equal(t, "P1D", d.String())
+}
+
+func TestIsZero(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"P0D", "PT0S", "P0Y0M0W0DT0H0M0S", "-PT0S", "PT0.0S"} {
+		d := MustFromString(s)
+		assert.True(t, d.IsZero(), s)
+	}
+	for _, s := range []string{"P1D", "PT0.000000001S", "-PT1S", "P0Y1M"} {
+		d := MustFromString(s)
+		assert.False(t, d.IsZero(), s)
+	}
+
+	assert.True(t, (&Duration{}).IsZero())
+	assert.True(t, (&Duration{Negative: true}).IsZero())
+	assert.False(t, (&Duration{Nanoseconds: -1}).IsZero())
+	assert.True(t, (*Duration)(nil).IsZero())
+
+	// a parsed zero duration is zero even though a component was present
+	d, info, err := FromStringWithInfo("P0D")
+	assert.NoError(t, err)
+	assert.True(t, info.StrictlyConformant)
+	assert.True(t, d.IsZero())
+	assert.Equal(t, &Duration{}, d)
+
+	// IsZero decides about the replacement of zero durations
+	s, err := FormatOptions{ZeroValue: "PT0S"}.Format(MustFromString("-P0D"))
+	assert.NoError(t, err)
+	assert.Equal(t, "PT0S", s)
+}
+
+func TestFromStringExplicitZero(t *testing.T) {
+	t.Parallel()
+
+	// zero components count as present, unlike in P or PT
+	for _, s := range []string{"PT0S", "P0D", "P0Y0M0D", "P0W", "PT0H0M", "-P0D", "+PT0S"} {
+		d, err := FromString(s)
+		assert.NoError(t, err, s)
+		assert.True(t, d.IsZero(), s)
+
+		d, err = FromStringRFC3339(strings.TrimLeft(s, "-+"))
+		if s != "P0W" {
+			assert.NoError(t, err, s)
+			assert.True(t, d.IsZero(), s)
+		}
+	}
+}
+
+func TestFromGoDuration(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]string{
+		"1h30m":    "PT1H30M",
+		"90m":      "PT1H30M",
+		"1.5s":     "PT1.5S",
+		"36h":      "PT36H",
+		"-1h30m":   "-PT1H30M",
+		"250ms":    "PT0.25S",
+		"1m0.5s":   "PT1M0.5S",
+		"0":        "PT0S",
+		"1h2m3.4s": "PT1H2M3.4S",
+	} {
+		d, err := FromGoDuration(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, d.String(), input)
+	}
+
+	for _, s := range []string{"", "1d", "PT1H", "1h30", "h"} {
+		d, err := FromGoDuration(s)
+		assert.Nil(t, d, s)
+		assert.ErrorIs(t, err, ErrBadFormat, s)
+	}
+}
+
+func TestFromSeconds(t *testing.T) {
+	t.Parallel()
+
+	for secs, expected := range map[float64]string{
+		30:           "PT30S",
+		90:           "PT90S",
+		1.5:          "PT1.5S",
+		-2.25:        "-PT2.25S",
+		0:            "PT0S",
+		0.1:          "PT0.1S",
+		1e-9:         "PT0.000000001S",
+		0.9999999999: "PT1S",
+	} {
+		d, err := FromSeconds(secs)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, d.String(), "%g", secs)
+		assert.NoError(t, d.Validate())
+	}
+
+	for _, secs := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e19, -1e19} {
+		d, err := FromSeconds(secs)
+		assert.Nil(t, d)
+		assert.ErrorIs(t, err, ErrOutOfRange, "%g", secs)
+	}
+}
+
+func TestNegate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d        Duration
+		expected Duration
+	}{
+		{Duration{Days: 1}, Duration{Negative: true, Days: 1}},
+		{Duration{Negative: true, Days: 1}, Duration{Days: 1}},
+		{Duration{Days: -1, Hours: -2}, Duration{Days: 1, Hours: 2}},
+		{Duration{Negative: true, Days: -1}, Duration{Negative: true, Days: 1}},
+		{Duration{Seconds: 1, Nanoseconds: 5e8}, Duration{Negative: true, Seconds: 1, Nanoseconds: 5e8}},
+		// mixed signs are flipped one by one
+		{Duration{Days: 1, Hours: -1}, Duration{Days: -1, Hours: 1}},
+		{Duration{Negative: true, Days: 1, Hours: -1}, Duration{Negative: true, Days: -1, Hours: 1}},
+		{Duration{Seconds: 1, Nanoseconds: -5e8}, Duration{Seconds: -1, Nanoseconds: 5e8}},
+		{Duration{}, Duration{Negative: true}},
+	} {
+		d := tc.d
+		n := d.Negate()
+		assert.Equal(t, &tc.expected, n, "%+v", tc.d)
+		assert.Equal(t, tc.d, d, "%+v: input modified", tc.d)
+		assert.True(t, d.Add(n).IsZero(), "%+v", tc.d)
+		assert.Equal(t, -d.ToEstimatedDuration(), n.ToEstimatedDuration(), "%+v", tc.d)
+		assert.True(t, n.Negate().Equal(&d), "%+v: double negation", tc.d)
+	}
+
+	// negating composes with Add and Sub
+	a, b := MustFromString("P1DT2H"), MustFromString("PT30M")
+	assert.Equal(t, a.Sub(b), a.Add(b.Negate()))
+	assert.Equal(t, a.Add(b), a.Sub(b.Negate()))
+	assert.Equal(t, a.Add(b).Negate(), a.Negate().Sub(b))
+
+	// formatting round-trips with a leading minus
+	for _, s := range []string{"P1D", "PT1H30M", "P1Y2M3W4DT5H6M7.5S"} {
+		d := MustFromString(s)
+		neg, err := d.Negate().Format()
+		assert.NoError(t, err)
+		assert.Equal(t, "-"+s, neg)
+		assert.Equal(t, d, MustFromString(neg).Negate())
+	}
+
+	assert.Nil(t, (*Duration)(nil).Negate())
+}
+
+func TestAbs(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d        Duration
+		expected Duration
+	}{
+		{Duration{Days: 1}, Duration{Days: 1}},
+		{Duration{Negative: true, Days: 1, Hours: 2}, Duration{Days: 1, Hours: 2}},
+		{Duration{Days: -1, Hours: -2}, Duration{Days: 1, Hours: 2}},
+		{Duration{Negative: true, Days: -1}, Duration{Days: 1}},
+		{Duration{Seconds: -1, Nanoseconds: -5e8}, Duration{Seconds: 1, Nanoseconds: 5e8}},
+		// mixed signs are carried first
+		{Duration{Days: 1, Hours: -2}, Duration{Hours: 22}},
+		{Duration{Days: -1, Hours: 2}, Duration{Hours: 22}},
+		{Duration{Negative: true, Days: 1, Hours: -2}, Duration{Hours: 22}},
+		{Duration{Weeks: 1, Days: -8}, Duration{Days: 1}},
+		{Duration{Minutes: 1, Seconds: -90}, Duration{Seconds: 30}},
+		// calendar components are left to the estimate
+		{Duration{Months: 1, Days: -1}, Duration{Months: 1, Days: -1}},
+		{Duration{Months: -1, Days: 1}, Duration{Months: 1, Days: -1}},
+		{Duration{Negative: true}, Duration{}},
+	} {
+		d := tc.d
+		a := d.Abs()
+		assert.Equal(t, &tc.expected, a, "%+v", tc.d)
+		assert.Equal(t, tc.d, d, "%+v: input modified", tc.d)
+		assert.GreaterOrEqual(t, int64(a.ToEstimatedDuration()), int64(0), "%+v", tc.d)
+	}
+}
+
+func TestUntilSince(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.November, 2, 8, 30, 0, 0, time.UTC)
+	clock := newFakeClock(now)
+
+	for _, tc := range []struct {
+		t     time.Time
+		until string
+	}{
+		{time.Date(2025, time.March, 14, 12, 0, 0, 0, time.UTC), "P4M12DT3H30M"},
+		{time.Date(2024, time.November, 2, 8, 30, 0, 0, time.UTC), "PT0S"},
+		{time.Date(2024, time.November, 2, 8, 30, 0, 500, time.UTC), "PT0.0000005S"},
+		{time.Date(2023, time.October, 1, 8, 0, 0, 0, time.UTC), "-P1Y1M1DT30M"},
+		{time.Date(2026, time.November, 2, 8, 30, 0, 0, time.UTC), "P2Y"},
+	} {
+		until := UntilWithClock(tc.t, clock)
+		assert.Equal(t, tc.until, until.String(), "until %s", tc.t)
+		since := SinceWithClock(tc.t, clock)
+		assert.True(t, until.Negate().EqualNormalized(since), "since %s: %s", tc.t, since)
+		assert.Equal(t, tc.t.Sub(now), until.ToDuration(now), "until %s", tc.t)
+	}
+
+	// days are counted in the location of t
+	berlin := loadLocation(t, "Europe/Berlin")
+	clock.set(time.Date(2024, time.March, 30, 12, 0, 0, 0, berlin).UTC())
+	expiry := time.Date(2024, time.April, 1, 12, 0, 0, 0, berlin)
+	assert.Equal(t, "P2D", UntilWithClock(expiry, clock).String())
+	assert.Equal(t, "-P2D", SinceWithClock(expiry, clock).String())
+
+	// the real clock is used otherwise
+	assert.True(t, Until(time.Now().Add(time.Hour)).Hours <= 1)
+	assert.Equal(t, 1, Since(time.Now().AddDate(-1, 0, -1)).Years)
+	assert.True(t, Until(time.Now().Add(-time.Hour)).Negative)
+}
@@ -0,0 +1,121 @@
+package iso8601duration
+
+import (
+	"math/big"
+	"time"
+)
+
+// Lengths of years and months for use in an Estimator
+const (
+	// YearCommon is a year of 365 days, used by ToEstimatedDuration
+	YearCommon = 365 * 24 * time.Hour
+	// YearJulian is the average year of the Julian calendar, 365.25 days
+	YearJulian = 8766 * time.Hour
+	// YearGregorian is the average year of the Gregorian calendar,
+	// 365.2425 days
+	YearGregorian = 31556952 * time.Second
+
+	// MonthCommon is a month of 30 days, used by ToEstimatedDuration
+	MonthCommon = 30 * 24 * time.Hour
+	// MonthAverage is a month of 30.44 days, as commonly used for
+	// averages
+	MonthAverage = 2630016 * time.Second
+	// MonthGregorian is a twelfth of YearGregorian
+	MonthGregorian = YearGregorian / 12
+)
+
+// Estimator converts durations into time.Duration using fixed lengths
+// for years and months. The zero value estimates exactly like
+// ToEstimatedDuration, that is with YearCommon and MonthCommon.
+type Estimator struct {
+	// Year is the length of a year, YearCommon if zero
+	Year time.Duration
+	// Month is the length of a month, MonthCommon if zero
+	Month time.Duration
+}
+
+// Estimate returns the inaccurate length of d
+func (e Estimator) Estimate(d *Duration) time.Duration {
+	lengths := e.lengths()
+	d = d.signed()
+
+	tot := time.Duration(0)
+	for i, v := range d.components() {
+		tot += lengths[i] * time.Duration(v)
+	}
+	return tot + time.Duration(d.Nanoseconds)
+}
+
+// Compare works like Duration.Compare, but uses the lengths of years
+// and months given by e. Durations beyond the range of time.Duration,
+// e.g. P300Y, are compared exactly as well.
+func (e Estimator) Compare(a, b *Duration) int {
+	ea, oka := e.estimate(a)
+	eb, okb := e.estimate(b)
+	if !oka || !okb {
+		return e.total(a).Cmp(e.total(b))
+	}
+	switch {
+	case ea < eb:
+		return -1
+	case ea > eb:
+		return 1
+	}
+	return 0
+}
+
+// estimate works like Estimate and reports whether the result did not
+// overflow
+func (e Estimator) estimate(d *Duration) (time.Duration, bool) {
+	lengths := e.lengths()
+	d = d.signed()
+
+	tot := time.Duration(d.Nanoseconds)
+	for i, v := range d.components() {
+		if v == 0 {
+			continue
+		}
+		p := lengths[i] * time.Duration(v)
+		if p/time.Duration(v) != lengths[i] {
+			return 0, false
+		}
+		sum := tot + p
+		if (sum > tot) != (p > 0) {
+			return 0, false
+		}
+		tot = sum
+	}
+	return tot, true
+}
+
+// total returns the estimate of d in nanoseconds without overflowing
+func (e Estimator) total(d *Duration) *big.Int {
+	lengths := e.lengths()
+	d = d.signed()
+
+	tot := big.NewInt(int64(d.Nanoseconds))
+	for i, v := range d.components() {
+		c := big.NewInt(int64(v))
+		tot.Add(tot, c.Mul(c, big.NewInt(int64(lengths[i]))))
+	}
+	return tot
+}
+
+// lengths returns the lengths of the components ordered like
+// components
+func (e Estimator) lengths() [7]time.Duration {
+	lengths := estimatedLengths
+	if e.Year != 0 {
+		lengths[0] = e.Year
+	}
+	if e.Month != 0 {
+		lengths[1] = e.Month
+	}
+	return lengths
+}
+
+// ToEstimatedDurationWith works like ToEstimatedDuration, but uses
+// the lengths of years and months given by e
+func (d *Duration) ToEstimatedDurationWith(e Estimator) time.Duration {
+	return e.Estimate(d)
+}
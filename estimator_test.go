@@ -0,0 +1,66 @@
+package iso8601duration
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimator(t *testing.T) {
+	t.Parallel()
+
+	day := time.Hour * 24
+	assert.Equal(t, day*365, YearCommon)
+	assert.Equal(t, day*365+day/4, YearJulian)
+	assert.Equal(t, time.Duration(365.2425*float64(day)), YearGregorian)
+	assert.Equal(t, day*30, MonthCommon)
+	assert.Equal(t, time.Duration(30.44*float64(day)), MonthAverage)
+	assert.Equal(t, time.Duration(30.436875*float64(day)), MonthGregorian)
+
+	d, err := FromString("P1Y2M3DT4H")
+	assert.NoError(t, err)
+
+	// the zero value matches ToEstimatedDuration
+	assert.Equal(t, d.ToEstimatedDuration(), Estimator{}.Estimate(d))
+	assert.Equal(t, d.ToEstimatedDuration(), d.ToEstimatedDurationWith(Estimator{Year: YearCommon, Month: MonthCommon}))
+
+	rest := day*3 + time.Hour*4
+	assert.Equal(t, YearGregorian+2*MonthGregorian+rest, d.ToEstimatedDurationWith(Estimator{Year: YearGregorian, Month: MonthGregorian}))
+	assert.Equal(t, YearJulian+2*MonthAverage+rest, d.ToEstimatedDurationWith(Estimator{Year: YearJulian, Month: MonthAverage}))
+
+	// months are independent of years
+	assert.Equal(t, YearJulian+2*MonthCommon+rest, d.ToEstimatedDurationWith(Estimator{Year: YearJulian}))
+
+	// twelve Gregorian months make a Gregorian year
+	e := Estimator{Year: YearGregorian, Month: MonthGregorian}
+	assert.Equal(t, e.Estimate(&Duration{Years: 1}), e.Estimate(&Duration{Months: 12}))
+
+	d.Negative = true
+	assert.Equal(t, -YearGregorian-2*MonthGregorian-rest, d.ToEstimatedDurationWith(e))
+
+	assert.Zero(t, (*Duration)(nil).ToEstimatedDurationWith(e))
+}
+
+func TestEstimatorCompare(t *testing.T) {
+	t.Parallel()
+
+	month, days := &Duration{Months: 1}, &Duration{Days: 30}
+	assert.Equal(t, 0, Estimator{}.Compare(month, days))
+	assert.Equal(t, month.Compare(days), Estimator{}.Compare(month, days))
+	assert.Equal(t, 1, Estimator{Month: MonthAverage}.Compare(month, days))
+	assert.Equal(t, -1, Estimator{Month: MonthAverage}.Compare(days, month))
+
+	year, months := &Duration{Years: 1}, &Duration{Months: 12}
+	assert.Equal(t, 1, Estimator{}.Compare(year, months))
+	assert.Equal(t, 0, Estimator{Year: YearGregorian, Month: MonthGregorian}.Compare(year, months))
+
+	// overflowing estimates are compared exactly
+	long, longer := &Duration{Years: 300}, &Duration{Years: 300, Nanoseconds: 1}
+	assert.Equal(t, -1, Estimator{}.Compare(long, longer))
+	assert.Equal(t, 1, Estimator{}.Compare(long, &Duration{Days: 1}))
+	assert.Equal(t, 1, Estimator{Year: YearGregorian}.Compare(long, &Duration{Months: 3600}))
+	assert.Equal(t, 0, Estimator{Year: YearGregorian, Month: MonthGregorian}.Compare(long, &Duration{Months: 3600}))
+	assert.Equal(t, -1, Estimator{}.Compare(&Duration{Seconds: math.MinInt64}, &Duration{Seconds: math.MaxInt64}))
+}
@@ -0,0 +1,73 @@
+package iso8601duration_test
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"text/template"
+	"time"
+
+	iso8601duration "github.com/toowoxx/go-iso8601duration"
+)
+
+func ExampleFuncs() {
+	data := struct {
+		Timeout *iso8601duration.Duration
+		Opts    iso8601duration.FormatOptions
+	}{
+		Timeout: &iso8601duration.Duration{Weeks: 1, Hours: 2},
+		Opts:    iso8601duration.FormatOptions{Weeks: iso8601duration.WeeksAsDays},
+	}
+
+	text := template.Must(template.New("text").
+		Funcs(iso8601duration.Funcs()).
+		Parse(`{{ humanizeDuration .Timeout }} ({{ formatDuration .Timeout .Opts }})` + "\n"))
+	if err := text.Execute(os.Stdout, data); err != nil {
+		panic(err)
+	}
+
+	html := htmltemplate.Must(htmltemplate.New("html").
+		Funcs(htmltemplate.FuncMap(iso8601duration.Funcs())).
+		Parse(`<time datetime="{{ formatDuration "P1DT12H" }}">{{ isoDuration "P1DT12H" | humanizeDuration }}</time>` + "\n"))
+	if err := html.Execute(os.Stdout, nil); err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// 1 week and 2 hours (P7DT2H)
+	// <time datetime="P1DT12H">1 day and 12 hours</time>
+}
+
+// fixedClock is a Clock which is stopped at a point in time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func (c fixedClock) NewTimer(time.Duration) (<-chan time.Time, func() bool) {
+	// the time never passes, so the timer never fires
+	return nil, func() bool { return true }
+}
+
+func ExampleUntil() {
+	// the NotAfter field of an x509.Certificate
+	notAfter := time.Date(2025, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+	// use Until(notAfter) with the current time
+	for _, now := range []time.Time{
+		time.Date(2024, time.November, 2, 8, 30, 0, 0, time.UTC),
+		time.Date(2025, time.March, 17, 9, 0, 0, 0, time.UTC),
+	} {
+		left := iso8601duration.UntilWithClock(notAfter, fixedClock(now))
+		if left.Negative {
+			fmt.Printf("certificate expired %s ago\n", left.Abs().HumanizeN(2))
+		} else {
+			fmt.Printf("certificate expires in %s (%s)\n", left.HumanizeN(2), left)
+		}
+	}
+
+	// Output:
+	// certificate expires in 4 months and 12 days (P4M12DT3H30M)
+	// certificate expired 2 days and 21 hours ago
+}
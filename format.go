@@ -0,0 +1,473 @@
+package iso8601duration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+)
+
+var (
+	// ErrMixedSigns is returned when formatting a duration whose components
+	// do not all share the same sign
+	ErrMixedSigns = errors.New("duration components have mixed signs")
+
+	// ErrNotWholeWeeks is returned by FormatWeeks when a duration can not
+	// be expressed in weeks only
+	ErrNotWholeWeeks = errors.New("duration is not a whole number of weeks")
+)
+
+// WeeksMode controls how FormatOptions emit weeks
+type WeeksMode int
+
+const (
+	// WeeksAsIs emits weeks and days as they are
+	WeeksAsIs WeeksMode = iota
+	// WeeksAsDays converts weeks into days
+	WeeksAsDays
+	// WeeksOnly expresses the duration in weeks only and fails with
+	// ErrNotWholeWeeks if that is not possible
+	WeeksOnly
+	// WeeksOrDays works like WeeksOnly, but falls back to WeeksAsDays
+	// instead of failing
+	WeeksOrDays
+)
+
+// FormatOptions controls the details of the textual representation.
+// The zero value formats exactly like Duration.Format.
+// FormatOptions are plain values, so a single set of options can be
+// shared and used concurrently.
+type FormatOptions struct {
+	// Weeks controls how weeks are emitted
+	Weeks WeeksMode
+	// ZeroComponents emits every component even if it is zero, e.g.
+	// P0Y0M0W0DT0H0M0S. The T designator is always present then.
+	// Weeks are left out if they are converted into days, which gives
+	// P0Y0M0DT0H0M0S.
+	ZeroComponents bool
+	// ZeroValue replaces the output for durations without any non-zero
+	// component. If empty, such durations are emitted as "PT0S", the
+	// canonical zero duration. Set it to "P" for the output of earlier
+	// versions.
+	ZeroValue string
+	// DecimalComma uses a comma instead of a dot to separate the
+	// fraction of the seconds, e.g. PT1,5S
+	DecimalComma bool
+	// Width pads every component with leading zeros to at least Width
+	// digits, e.g. P01Y02M for a width of 2. Fractions of the seconds
+	// are not counted.
+	Width int
+
+	// precision is the number of fractional digits plus one, so that
+	// the zero value means trimming trailing zeros
+	precision int
+}
+
+// SecondsPrecision returns a copy of the options which emits exactly n
+// digits after the decimal separator of the seconds, e.g. PT1.500S for
+// n = 3. Fractions with more digits are rounded half to even. A
+// negative n trims trailing zeros, which is the default.
+func (o FormatOptions) SecondsPrecision(n int) FormatOptions {
+	if n < 0 {
+		o.precision = 0
+	} else {
+		o.precision = n + 1
+	}
+	return o
+}
+
+// Format returns the textual representation of d according to the
+// options. It fails under the same conditions as Duration.Format and,
+// depending on Weeks, with ErrNotWholeWeeks.
+func (o FormatOptions) Format(d *Duration) (string, error) {
+	var buf [32]byte
+	b, err := o.AppendFormat(buf[:0], d)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// AppendFormat works like Format, but appends to b and returns the
+// extended buffer
+func (o FormatOptions) AppendFormat(b []byte, d *Duration) ([]byte, error) {
+	if d == nil {
+		d = &Duration{}
+	}
+	if err := d.Validate(); err != nil {
+		return b, err
+	}
+	d, weeks, err := o.convertWeeks(d.canonical())
+	if err != nil {
+		return b, err
+	}
+	return o.appendFormat(b, d, weeks), nil
+}
+
+// Formatted couples a duration with the options used to marshal it,
+// so it can be handed to encoding packages, e.g.
+// json.Marshal(d.WithOptions(opts)).
+type Formatted struct {
+	*Duration
+	Options FormatOptions
+}
+
+// WithOptions returns the duration coupled with the options to format
+// it with
+func (d *Duration) WithOptions(opts FormatOptions) Formatted {
+	return Formatted{Duration: d, Options: opts}
+}
+
+// MarshalText implements encoding.TextMarshaler using the options
+func (f Formatted) MarshalText() ([]byte, error) {
+	return f.Options.AppendFormat(nil, f.Duration)
+}
+
+// String formats the duration using the options on a best-effort
+// basis, like Duration.String
+func (f Formatted) String() string {
+	d, weeks, err := f.Options.convertWeeks(f.Duration)
+	if err != nil {
+		d = f.Duration
+	}
+	var buf [32]byte
+	return string(f.Options.appendFormat(buf[:0], d, weeks))
+}
+
+// Format returns the textual representation of the duration.
+// Components which are all negative are emitted as a negative
+// duration, e.g. -P1D for Duration{Days: -1}.
+//
+// An error is returned if the duration cannot be represented faithfully:
+//   - ErrMixedSigns if some components are positive while others are
+//     negative, e.g. Duration{Hours: 5, Minutes: -10}
+//   - ErrOutOfRange if Nanoseconds exceeds a second
+//
+// Validate performs the same checks without formatting.
+func (d *Duration) Format() (string, error) {
+	return FormatOptions{}.Format(d)
+}
+
+// FormatWeeks returns the duration expressed in weeks only, e.g. P2W
+// for Duration{Days: 14}.
+//
+// ErrNotWholeWeeks is returned if the duration contains years, months
+// or a time part, or if its days do not add up to whole weeks.
+// Otherwise it fails under the same conditions as Format.
+func (d *Duration) FormatWeeks() (string, error) {
+	return FormatOptions{Weeks: WeeksOnly}.Format(d)
+}
+
+// FormatWeeksOrDays works like FormatWeeks, but falls back to Format
+// with weeks expanded into days if the duration is not a whole number
+// of weeks, e.g. P10D for Duration{Weeks: 1, Days: 3}.
+func (d *Duration) FormatWeeksOrDays() (string, error) {
+	return FormatOptions{Weeks: WeeksOrDays}.Format(d)
+}
+
+// String prints out the value passed in. It's not strictly according to the
+// ISO spec, but it's pretty close. In particular, to completely conform it
+// would need to round up to the next largest unit. 61 seconds to 1 minute 1
+// second, for example. It would also need to disallow weeks mingling with
+// other units.
+//
+// Weeks are emitted between months and days, e.g. P1Y2M3W4D, which is
+// the order ISO 8601-2 defines for combined weeks and the order
+// FromString expects. Parsers which only accept weeks on their own
+// can be served with FormatWeeks or FormatOptions{Weeks: WeeksAsDays}.
+//
+// Durations without any non-zero component are printed as PT0S, which
+// FromString parses back, rather than as P. The sign of a negative zero
+// is dropped. Use FormatOptions{ZeroValue: "P"} for the former output.
+//
+// Components which are all negative are emitted as a negative duration
+// like with Format, e.g. -P1DT2H for Duration{Days: -1, Hours: -2}.
+// String never fails. Durations which Format would reject are printed
+// on a best-effort basis, component by component.
+func (d *Duration) String() string {
+	var buf [32]byte
+	return string(FormatOptions{}.appendFormat(buf[:0], d, weeksIfSet))
+}
+
+// ShortestString works like String, but carries the time components
+// into the largest unit first, e.g. PT1H1M1S for PT3661S, see
+// Normalize. Days, weeks, months and years are left as they are, as
+// their length in hours depends on the calendar.
+func (d *Duration) ShortestString() string {
+	return d.Normalize().String()
+}
+
+// Components returns the textual representation without the leading
+// P designator, e.g. 1DT2H for P1DT2H or T30M for PT30M. The minus
+// sign of negative durations is kept.
+func (d *Duration) Components() string {
+	if d == nil {
+		return ""
+	}
+	var buf [32]byte
+	b := FormatOptions{}.appendFormat(buf[:0], d, weeksIfSet)
+	i := bytes.IndexByte(b, 'P')
+	return string(b[:i]) + string(b[i+1:])
+}
+
+// ISODatePart returns the components before the T designator like
+// String, e.g. P1Y2D for P1Y2DT3H. It returns "P" if there are none.
+func (d *Duration) ISODatePart() string {
+	return partString(d.DatePart())
+}
+
+// ISOTimePart returns the components after the T designator like
+// String, e.g. PT3H for P1Y2DT3H. It returns "P" if there are none.
+func (d *Duration) ISOTimePart() string {
+	return partString(d.TimePart())
+}
+
+// partString formats a part of a duration, which may be nil
+func partString(p *Duration) string {
+	if p == nil {
+		p = &Duration{}
+	}
+	var buf [32]byte
+	return string(FormatOptions{ZeroValue: "P"}.appendFormat(buf[:0], p, weeksIfSet))
+}
+
+// StringVerbose works like String but always emits every designator,
+// including zero components, e.g. P0Y0M0W0DT0H0M0S. This is useful
+// for fixed-width output.
+func (d *Duration) StringVerbose() string {
+	var buf [32]byte
+	return string(FormatOptions{ZeroComponents: true}.appendFormat(buf[:0], d, weeksIfSet))
+}
+
+// MarshalText implements encoding.TextMarshaler. It fails under the
+// same conditions as Format.
+func (d *Duration) MarshalText() ([]byte, error) {
+	return FormatOptions{}.AppendFormat(nil, d)
+}
+
+// AppendTo appends the textual representation of the duration, as
+// returned by String, to b and returns the extended buffer. It only
+// allocates if b needs to grow.
+func (d *Duration) AppendTo(b []byte) []byte {
+	return FormatOptions{}.appendFormat(b, d, weeksIfSet)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := FromString(string(text))
+	if err != nil {
+		return err
+	}
+	*d = *parsed
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Strings are parsed with
+// FromString, while numbers are taken as seconds like in FromSeconds,
+// e.g. 30 for PT30S. Null leaves the duration unchanged.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(s))
+	}
+
+	secs, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return ErrBadFormat
+	}
+	parsed, err := FromSeconds(secs)
+	if err != nil {
+		return err
+	}
+	*d = *parsed
+	return nil
+}
+
+func (d *Duration) hasMixedSigns() bool {
+	var pos, neg bool
+	for _, v := range d.components() {
+		pos = pos || v > 0
+		neg = neg || v < 0
+	}
+	if d != nil {
+		pos = pos || d.Nanoseconds > 0
+		neg = neg || d.Nanoseconds < 0
+	}
+	return pos && neg
+}
+
+// weeksEmit tells appendFormat when to emit the weeks
+type weeksEmit int
+
+const (
+	// weeksIfSet emits the weeks if they are non-zero or zero
+	// components are requested
+	weeksIfSet weeksEmit = iota
+	// weeksAlways emits the weeks even if zero
+	weeksAlways
+	// weeksNever omits the weeks, as they have been converted
+	weeksNever
+)
+
+// convertWeeks applies the weeks mode to d
+func (o FormatOptions) convertWeeks(d *Duration) (*Duration, weeksEmit, error) {
+	if d == nil {
+		return nil, weeksIfSet, nil
+	}
+	switch o.Weeks {
+	case WeeksAsDays:
+		return d.ExpandWeeksToDays(), weeksNever, nil
+	case WeeksOnly, WeeksOrDays:
+		days := d.Weeks*7 + d.Days
+		if d.Years != 0 || d.Months != 0 || d.HasTimePart() || days%7 != 0 {
+			if o.Weeks == WeeksOrDays {
+				return d.ExpandWeeksToDays(), weeksNever, nil
+			}
+			return nil, weeksIfSet, ErrNotWholeWeeks
+		}
+		return &Duration{Negative: d.Negative, Weeks: days / 7}, weeksAlways, nil
+	}
+	return d, weeksIfSet, nil
+}
+
+// appendFormat appends d on a best-effort basis
+func (o FormatOptions) appendFormat(b []byte, d *Duration, weeks weeksEmit) []byte {
+	if d == nil {
+		return append(b, "<nil>"...)
+	}
+	// fold the fraction and components which are all negative into a
+	// single sign like Format, so that only durations with mixed signs
+	// are printed component by component
+	c := *d
+	if s, ok := addInt(c.Seconds, c.Nanoseconds/1e9); ok {
+		c.Seconds, c.Nanoseconds = shareSign(s, c.Nanoseconds%1e9)
+	}
+	if !c.hasMixedSigns() {
+		c = *c.canonical()
+	}
+	d = &c
+
+	zeros := o.ZeroComponents
+	if !zeros && d.IsZero() && (o.ZeroValue != "" || weeks != weeksAlways) {
+		if o.ZeroValue == "" {
+			return append(b, "PT0S"...)
+		}
+		return append(b, o.ZeroValue...)
+	}
+
+	if d.Negative {
+		b = append(b, '-')
+	}
+	b = append(b, 'P')
+	b = o.appendComponent(b, d.Years, 'Y', zeros)
+	b = o.appendComponent(b, d.Months, 'M', zeros)
+	if weeks != weeksNever {
+		b = o.appendComponent(b, d.Weeks, 'W', zeros || weeks == weeksAlways)
+	}
+	b = o.appendComponent(b, d.Days, 'D', zeros)
+	if zeros || d.HasTimePart() {
+		b = append(b, 'T')
+	}
+	b = o.appendComponent(b, d.Hours, 'H', zeros)
+	b = o.appendComponent(b, d.Minutes, 'M', zeros)
+	b = o.appendSeconds(b, d.Seconds, d.Nanoseconds, zeros)
+	return b
+}
+
+// appendSeconds appends the seconds including their fraction
+func (o FormatOptions) appendSeconds(b []byte, secs, nanos int, zeros bool) []byte {
+	if secs == 0 && nanos == 0 && !zeros {
+		return b
+	}
+	if secs < 0 || nanos < 0 {
+		b = append(b, '-')
+		secs, nanos = -secs, -nanos
+	}
+
+	frac, digits := nanos, 9
+	if o.precision > 0 {
+		if digits > o.precision-1 {
+			digits = o.precision - 1
+		}
+		secs, frac = roundFraction(secs, nanos, digits)
+	} else {
+		for digits > 0 && frac%10 == 0 {
+			frac /= 10
+			digits--
+		}
+	}
+
+	b = o.appendInt(b, secs)
+	if digits > 0 {
+		if o.DecimalComma {
+			b = append(b, ',')
+		} else {
+			b = append(b, '.')
+		}
+		b = appendPadded(b, frac, digits)
+		for i := digits; i < o.precision-1; i++ {
+			b = append(b, '0')
+		}
+	}
+	return append(b, 'S')
+}
+
+// roundFraction rounds the nanoseconds half to even to n fractional
+// digits and returns the seconds and the digits of the fraction.
+// Rounding up may carry into the seconds.
+func roundFraction(secs, nanos int, n int) (int, int) {
+	if n >= 9 {
+		return secs, nanos
+	}
+	unit := pow10(9 - n)
+	q, r := nanos/unit, nanos%unit
+
+	last := q
+	if n == 0 {
+		last = secs
+	}
+	if r > unit/2 || (r == unit/2 && last%2 == 1) {
+		q++
+	}
+	if q == pow10(n) {
+		return secs + 1, 0
+	}
+	return secs, q
+}
+
+func pow10(n int) int {
+	p := 1
+	for ; n > 0; n-- {
+		p *= 10
+	}
+	return p
+}
+
+// appendComponent appends a single component followed by its designator.
+// Zero components are skipped unless zeros is set.
+func (o FormatOptions) appendComponent(b []byte, val int, designator byte, zeros bool) []byte {
+	if val == 0 && !zeros {
+		return b
+	}
+	b = o.appendInt(b, val)
+	return append(b, designator)
+}
+
+// appendInt appends val padded to the width of the options
+func (o FormatOptions) appendInt(b []byte, val int) []byte {
+	if o.Width <= 1 || val == math.MinInt {
+		return strconv.AppendInt(b, int64(val), 10)
+	}
+	if val < 0 {
+		b = append(b, '-')
+		val = -val
+	}
+	return appendPadded(b, val, o.Width)
+}
@@ -0,0 +1,685 @@
+package iso8601duration
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Years: 1, Days: 2, Hours: 3}
+	s, err := d.Format()
+	assert.Nil(t, err)
+	assert.Equal(t, "P1Y2DT3H", s)
+
+	// mixed signs can not be represented
+	d = Duration{Hours: 5, Minutes: -10}
+	_, err = d.Format()
+	assert.Equal(t, ErrMixedSigns, err)
+
+	// String still prints something instead of panicking
+	assert.NotPanics(t, func() {
+		assert.Equal(t, "PT5H-10M", d.String())
+	})
+}
+
+func TestMarshalText(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Weeks: 2}
+	text, err := d.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "P2W", string(text))
+
+	d = Duration{Days: -1, Hours: 1}
+	_, err = d.MarshalText()
+	assert.Equal(t, ErrMixedSigns, err)
+
+	var parsed Duration
+	assert.Nil(t, parsed.UnmarshalText([]byte("P1DT2H")))
+	assert.Equal(t, Duration{Days: 1, Hours: 2}, parsed)
+	assert.Equal(t, ErrBadFormat, parsed.UnmarshalText([]byte("asdf")))
+
+	// encoding packages pick up the text marshaling
+	out, err := json.Marshal(&Duration{Minutes: 30})
+	assert.Nil(t, err)
+	assert.Equal(t, `"PT30M"`, string(out))
+
+	_, err = json.Marshal(&Duration{Minutes: 30, Seconds: -1})
+	assert.ErrorIs(t, err, ErrMixedSigns)
+}
+
+func TestStringVerbose(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{}
+	assert.Equal(t, "P0Y0M0W0DT0H0M0S", d.StringVerbose())
+
+	d = Duration{Years: 1, Hours: 2}
+	assert.Equal(t, "P1Y0M0W0DT2H0M0S", d.StringVerbose())
+
+	d = Duration{Weeks: 3, Seconds: 45}
+	assert.Equal(t, "P0Y0M3W0DT0H0M45S", d.StringVerbose())
+
+	// the verbose form parses back to the same duration
+	d = Duration{Months: 4, Minutes: 5}
+	parsed, err := FromString(d.StringVerbose())
+	assert.Nil(t, err)
+	assert.Equal(t, d, *parsed)
+}
+
+func TestShortestString(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]string{
+		"PT3661S":      "PT1H1M1S",
+		"PT60S":        "PT1M",
+		"PT3600S":      "PT1H",
+		"PT90M":        "PT1H30M",
+		"PT1.5S":       "PT1.5S",
+		"PT48H":        "PT48H",
+		"P1W10DT125M":  "P1W10DT2H5M",
+		"P1Y13M":       "P1Y13M",
+		"-PT120S":      "-PT2M",
+		"PT0S":         "PT0S",
+		"P1DT59M3600S": "P1DT1H59M",
+	} {
+		d, err := FromString(input)
+		assert.Nil(t, err, input)
+		assert.Equal(t, expected, d.ShortestString(), input)
+	}
+
+	d := Duration{Hours: 1, Minutes: -30}
+	assert.Equal(t, "PT30M", d.ShortestString())
+}
+
+func TestISODateTimePart(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		input, date, time string
+	}{
+		{"P1Y2DT3H", "P1Y2D", "PT3H"},
+		{"P1Y2M3W4DT5H6M7.5S", "P1Y2M3W4D", "PT5H6M7.5S"},
+		{"P1Y2D", "P1Y2D", "P"},
+		{"P2W", "P2W", "P"},
+		{"PT3H", "P", "PT3H"},
+		{"PT0.5S", "P", "PT0.5S"},
+		{"-P1DT12H", "-P1D", "-PT12H"},
+		{"-PT1M", "P", "-PT1M"},
+		{"PT0S", "P", "P"},
+	} {
+		d := MustFromString(tc.input)
+		assert.Equal(t, tc.date, d.ISODatePart(), tc.input)
+		assert.Equal(t, tc.time, d.ISOTimePart(), tc.input)
+	}
+
+	var d *Duration
+	assert.Equal(t, "P", d.ISODatePart())
+	assert.Equal(t, "P", d.ISOTimePart())
+}
+
+func TestAppendTo(t *testing.T) {
+	d := Duration{Years: 1, Days: 2, Hours: 3, Minutes: 4, Seconds: 5}
+	assert.Equal(t, "P1Y2DT3H4M5S", string(d.AppendTo(nil)))
+
+	b := []byte("duration=")
+	b = d.AppendTo(b)
+	assert.Equal(t, "duration=P1Y2DT3H4M5S", string(b))
+
+	d = Duration{Negative: true, Weeks: 2}
+	assert.Equal(t, "-P2W", string(d.AppendTo(nil)))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		b = d.AppendTo(b[:0])
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func BenchmarkAppendTo(b *testing.B) {
+	d := Duration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6}
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = d.AppendTo(buf[:0])
+	}
+}
+
+func TestFormatWeeks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d    Duration
+		want string
+		err  error
+	}{
+		{Duration{Days: 14}, "P2W", nil},
+		{Duration{Weeks: 1, Days: 7}, "P2W", nil},
+		{Duration{Weeks: 3}, "P3W", nil},
+		{Duration{}, "P0W", nil},
+		{Duration{Negative: true, Days: 7}, "-P1W", nil},
+		{Duration{Weeks: 1, Days: 3}, "", ErrNotWholeWeeks},
+		{Duration{Months: 1}, "", ErrNotWholeWeeks},
+		{Duration{Years: 1}, "", ErrNotWholeWeeks},
+		{Duration{Hours: 1}, "", ErrNotWholeWeeks},
+		{Duration{Weeks: 1, Hours: 1}, "", ErrNotWholeWeeks},
+	}
+
+	for _, test := range tests {
+		s, err := test.d.FormatWeeks()
+		assert.Equal(t, test.err, err, test.d.String())
+		assert.Equal(t, test.want, s, test.d.String())
+	}
+}
+
+func TestFormatWeeksOrDays(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d    Duration
+		want string
+	}{
+		{Duration{Days: 14}, "P2W"},
+		{Duration{Weeks: 1, Days: 3}, "P10D"},
+		{Duration{Months: 1}, "P1M"},
+		{Duration{Hours: 1}, "PT1H"},
+		{Duration{Weeks: 2, Hours: 1}, "P14DT1H"},
+	}
+
+	for _, test := range tests {
+		s, err := test.d.FormatWeeksOrDays()
+		assert.Nil(t, err)
+		assert.Equal(t, test.want, s, test.d.String())
+	}
+
+	_, err := (&Duration{Days: 1, Hours: -1}).FormatWeeksOrDays()
+	assert.Equal(t, ErrMixedSigns, err)
+}
+
+func TestFormatOptions(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Weeks: 1, Days: 3, Hours: 4}
+
+	// the zero value formats like Format
+	s, err := FormatOptions{}.Format(&d)
+	assert.Nil(t, err)
+	assert.Equal(t, "P1W3DT4H", s)
+
+	s, err = FormatOptions{Weeks: WeeksAsDays}.Format(&d)
+	assert.Nil(t, err)
+	assert.Equal(t, "P10DT4H", s)
+
+	_, err = FormatOptions{Weeks: WeeksOnly}.Format(&d)
+	assert.Equal(t, ErrNotWholeWeeks, err)
+
+	s, err = FormatOptions{Weeks: WeeksOrDays}.Format(&Duration{Days: 21})
+	assert.Nil(t, err)
+	assert.Equal(t, "P3W", s)
+
+	s, err = FormatOptions{ZeroComponents: true, Weeks: WeeksAsDays}.Format(&d)
+	assert.Nil(t, err)
+	assert.Equal(t, "P0Y0M10DT4H0M0S", s)
+
+	opts := FormatOptions{ZeroValue: "PT0S"}
+	s, err = opts.Format(&Duration{})
+	assert.Nil(t, err)
+	assert.Equal(t, "PT0S", s)
+	s, err = opts.Format(&d)
+	assert.Nil(t, err)
+	assert.Equal(t, "P1W3DT4H", s)
+
+	_, err = opts.Format(&Duration{Days: 1, Hours: -1})
+	assert.Equal(t, ErrMixedSigns, err)
+
+	b, err := FormatOptions{Weeks: WeeksAsDays}.AppendFormat([]byte("d="), &d)
+	assert.Nil(t, err)
+	assert.Equal(t, "d=P10DT4H", string(b))
+}
+
+func TestFormatZeroValue(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []*Duration{{}, {Negative: true}, MustFromString("P0D"), MustFromString("-PT0S")} {
+		assert.Equal(t, "PT0S", d.String(), "%+v", d)
+
+		s, err := d.Format()
+		assert.NoError(t, err)
+		assert.Equal(t, "PT0S", s)
+
+		b, err := d.MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, "PT0S", string(b))
+
+		// the output parses back to a zero duration
+		var parsed Duration
+		assert.NoError(t, parsed.UnmarshalText(b))
+		assert.True(t, parsed.IsZero())
+	}
+
+	// the former output is still available
+	s, err := FormatOptions{ZeroValue: "P"}.Format(&Duration{})
+	assert.NoError(t, err)
+	assert.Equal(t, "P", s)
+
+	// weeks only output keeps its designator
+	s, err = (&Duration{}).FormatWeeks()
+	assert.NoError(t, err)
+	assert.Equal(t, "P0W", s)
+}
+
+func TestFormatted(t *testing.T) {
+	t.Parallel()
+
+	opts := FormatOptions{Weeks: WeeksAsDays, ZeroValue: "P0D"}
+
+	type config struct {
+		Timeout Formatted `json:"timeout"`
+		Retry   Formatted `json:"retry"`
+	}
+	out, err := json.Marshal(config{
+		Timeout: (&Duration{Weeks: 2}).WithOptions(opts),
+		Retry:   (&Duration{}).WithOptions(opts),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"timeout":"P14D","retry":"P0D"}`, string(out))
+
+	f := (&Duration{Weeks: 1, Hours: 1}).WithOptions(FormatOptions{Weeks: WeeksOnly})
+	_, err = f.MarshalText()
+	assert.Equal(t, ErrNotWholeWeeks, err)
+	assert.Equal(t, "P1WT1H", f.String())
+}
+
+func TestComponents(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "1DT2H", (&Duration{Days: 1, Hours: 2}).Components())
+	assert.Equal(t, "1Y2M3W", (&Duration{Years: 1, Months: 2, Weeks: 3}).Components())
+	assert.Equal(t, "T30M", (&Duration{Minutes: 30}).Components())
+	assert.Equal(t, "T1H2M3S", (&Duration{Hours: 1, Minutes: 2, Seconds: 3}).Components())
+	assert.Equal(t, "-1D", (&Duration{Negative: true, Days: 1}).Components())
+	assert.Equal(t, "T0S", (&Duration{}).Components())
+}
+
+func TestFormatZeroComponents(t *testing.T) {
+	t.Parallel()
+
+	opts := FormatOptions{ZeroComponents: true, Weeks: WeeksAsDays}
+
+	tests := []struct {
+		d    Duration
+		want string
+	}{
+		{Duration{}, "P0Y0M0DT0H0M0S"},
+		{Duration{Years: 1}, "P1Y0M0DT0H0M0S"},
+		{Duration{Weeks: 1, Days: 1, Seconds: 5}, "P0Y0M8DT0H0M5S"},
+		{Duration{Negative: true, Hours: 12}, "-P0Y0M0DT12H0M0S"},
+	}
+
+	for _, test := range tests {
+		s, err := opts.Format(&test.d)
+		assert.Nil(t, err)
+		assert.Equal(t, test.want, s)
+
+		// the parser accepts explicit zero components
+		parsed, err := FromString(s)
+		assert.Nil(t, err)
+		assert.Equal(t, test.d.ExpandWeeksToDays(), parsed)
+	}
+
+	// the default formatter is not affected
+	assert.Equal(t, "P1Y", (&Duration{Years: 1}).String())
+}
+
+func TestFormatFraction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d    Duration
+		want string
+	}{
+		{Duration{Seconds: 1, Nanoseconds: 500000000}, "PT1.5S"},
+		{Duration{Nanoseconds: 1000000}, "PT0.001S"},
+		{Duration{Nanoseconds: 1}, "PT0.000000001S"},
+		{Duration{Minutes: 1, Nanoseconds: 120000000}, "PT1M0.12S"},
+		{Duration{Negative: true, Seconds: 2, Nanoseconds: 250000000}, "-PT2.25S"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.d.String())
+
+		parsed, err := FromString(test.want)
+		assert.Nil(t, err)
+		assert.Equal(t, test.d, *parsed)
+	}
+}
+
+func TestFractionLossless(t *testing.T) {
+	t.Parallel()
+
+	d := MustFromString("PT1.001S")
+	assert.Equal(t, &Duration{Seconds: 1, Nanoseconds: 1000000}, d)
+	assert.Equal(t, time.Second+time.Millisecond, d.ToEstimatedDuration())
+
+	property := func(secs uint32, nanos uint32, neg bool) bool {
+		nanos %= 1e9
+		d := &Duration{Negative: neg, Seconds: int(secs), Nanoseconds: int(nanos)}
+		if d.IsZero() {
+			d.Negative = false
+		}
+
+		// the fraction maps to the nanoseconds in both directions
+		parsed, err := FromString(d.String())
+		if err != nil || *parsed != *d {
+			return false
+		}
+		padded, err := FromString(fmt.Sprintf("PT%d.%09dS", secs, nanos))
+		if err != nil || padded.Nanoseconds != int(nanos) {
+			return false
+		}
+
+		// subtracting a fraction from whole seconds borrows a second
+		diff := (&Duration{Seconds: int(secs)}).Sub(&Duration{Nanoseconds: int(nanos)})
+		formatted, err := diff.Format()
+		if err != nil || diff.hasMixedSigns() {
+			return false
+		}
+		parsed, err = FromString(formatted)
+		if err != nil || parsed.ToEstimatedDuration() != time.Duration(secs)*time.Second-time.Duration(nanos) {
+			return false
+		}
+
+		// and survives the conversions and arithmetic
+		td := time.Duration(secs)*time.Second + time.Duration(nanos)
+		if neg {
+			td = -td
+		}
+		return d.ToEstimatedDuration() == td &&
+			d.ToDuration(time.Unix(0, 0)) == td &&
+			d.Normalize().ToEstimatedDuration() == td &&
+			d.Add(d).ToEstimatedDuration() == 2*td &&
+			d.Sub(d).IsZero()
+	}
+	assert.NoError(t, quick.Check(property, &quick.Config{MaxCount: 2000}))
+}
+
+func TestFormatSecondsPrecision(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		opts FormatOptions
+		d    Duration
+		want string
+	}{
+		{FormatOptions{}.SecondsPrecision(3), Duration{Seconds: 1, Nanoseconds: 500000000}, "PT1.500S"},
+		{FormatOptions{}.SecondsPrecision(3), Duration{Seconds: 1}, "PT1.000S"},
+		{FormatOptions{}.SecondsPrecision(2), Duration{Seconds: 1, Nanoseconds: 5000000}, "PT1.00S"},
+		{FormatOptions{}.SecondsPrecision(2), Duration{Seconds: 1, Nanoseconds: 15000000}, "PT1.02S"},
+		{FormatOptions{}.SecondsPrecision(2), Duration{Seconds: 1, Nanoseconds: 25000000}, "PT1.02S"},
+		{FormatOptions{}.SecondsPrecision(2), Duration{Seconds: 1, Nanoseconds: 25000001}, "PT1.03S"},
+		{FormatOptions{}.SecondsPrecision(2), Duration{Seconds: 1, Nanoseconds: 123456789}, "PT1.12S"},
+		{FormatOptions{}.SecondsPrecision(0), Duration{Seconds: 1, Nanoseconds: 500000000}, "PT2S"},
+		{FormatOptions{}.SecondsPrecision(0), Duration{Seconds: 2, Nanoseconds: 500000000}, "PT2S"},
+		{FormatOptions{}.SecondsPrecision(12), Duration{Nanoseconds: 1}, "PT0.000000001000S"},
+		{FormatOptions{}.SecondsPrecision(-1), Duration{Seconds: 1, Nanoseconds: 500000000}, "PT1.5S"},
+		{FormatOptions{}.SecondsPrecision(3).SecondsPrecision(-1), Duration{Seconds: 1}, "PT1S"},
+
+		// rounding carries into the seconds
+		{FormatOptions{}.SecondsPrecision(2), Duration{Seconds: 59, Nanoseconds: 999000000}, "PT60.00S"},
+
+		// the comma separator
+		{FormatOptions{DecimalComma: true}, Duration{Seconds: 1, Nanoseconds: 500000000}, "PT1,5S"},
+		{FormatOptions{DecimalComma: true}.SecondsPrecision(3), Duration{Seconds: 1, Nanoseconds: 500000000}, "PT1,500S"},
+		{FormatOptions{DecimalComma: true}.SecondsPrecision(1), Duration{Nanoseconds: 50000000}, "PT0,0S"},
+		{FormatOptions{DecimalComma: true}.SecondsPrecision(1), Duration{Nanoseconds: 150000000}, "PT0,2S"},
+		{FormatOptions{DecimalComma: true}, Duration{Seconds: 1}, "PT1S"},
+	}
+
+	for _, test := range tests {
+		s, err := test.opts.Format(&test.d)
+		assert.Nil(t, err)
+		assert.Equal(t, test.want, s)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := []*Duration{
+		nil,
+		{},
+		{Years: 1, Seconds: 2, Nanoseconds: 3},
+		{Negative: true, Days: 1},
+		{Days: -1, Hours: -2},
+		{Nanoseconds: 999999999},
+	}
+	for _, d := range valid {
+		assert.Nil(t, d.Validate(), d.String())
+	}
+
+	invalid := []struct {
+		d   Duration
+		err error
+	}{
+		{Duration{Hours: 5, Minutes: -10}, ErrMixedSigns},
+		{Duration{Years: -1, Seconds: 1}, ErrMixedSigns},
+		{Duration{Seconds: 1, Nanoseconds: -1}, ErrMixedSigns},
+		{Duration{Negative: true, Days: 1, Hours: -1}, ErrMixedSigns},
+		{Duration{Nanoseconds: 1000000000}, ErrOutOfRange},
+		{Duration{Seconds: -1, Nanoseconds: -1000000000}, ErrOutOfRange},
+	}
+	for _, test := range invalid {
+		assert.Equal(t, test.err, test.d.Validate(), test.d.String())
+
+		// formatting fails the same way
+		_, err := test.d.Format()
+		assert.Equal(t, test.err, err, test.d.String())
+	}
+}
+
+func TestStringMixedSeconds(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d    Duration
+		want string
+	}{
+		{Duration{Seconds: 5, Nanoseconds: -300000000}, "PT4.7S"},
+		{Duration{Seconds: -5, Nanoseconds: 300000000}, "-PT4.7S"},
+		{Duration{Negative: true, Seconds: 5, Nanoseconds: -300000000}, "-PT4.7S"},
+		{Duration{Seconds: 1, Nanoseconds: -1}, "PT0.999999999S"},
+		{Duration{Seconds: 1, Nanoseconds: -1500000000}, "-PT0.5S"},
+		{Duration{Nanoseconds: 1000000000}, "PT1S"},
+		{Duration{Minutes: 1, Nanoseconds: -500000000}, "PT1M-0.5S"},
+		{Duration{Minutes: 1, Seconds: -5, Nanoseconds: 300000000}, "PT1M-4.7S"},
+	} {
+		assert.Equal(t, tc.want, tc.d.String(), "%+v", tc.d)
+	}
+}
+
+func TestFormatNegativeComponents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d    Duration
+		want string
+	}{
+		{Duration{Days: -1}, "-P1D"},
+		{Duration{Days: -1, Hours: -2, Nanoseconds: -500000000}, "-P1DT2H0.5S"},
+		{Duration{Negative: true, Weeks: -2}, "P2W"},
+		{Duration{Days: -1, Hours: -2}, "-P1DT2H"},
+		{Duration{Seconds: -1, Nanoseconds: -500000000}, "-PT1.5S"},
+	}
+
+	for _, test := range tests {
+		s, err := test.d.Format()
+		assert.Nil(t, err)
+		assert.Equal(t, test.want, s)
+
+		// the best-effort printers fold the sign the same way
+		assert.Equal(t, test.want, test.d.String())
+		assert.Equal(t, test.want, string(test.d.AppendTo(nil)))
+		assert.Equal(t, strings.Replace(test.want, "P", "", 1), test.d.Components())
+		assert.Equal(t, test.want, test.d.WithOptions(FormatOptions{}).String())
+
+		// the output parses back to the same length
+		parsed, err := FromString(s)
+		assert.Nil(t, err)
+		assert.Equal(t, test.d.ToEstimatedDuration(), parsed.ToEstimatedDuration())
+	}
+}
+
+func TestStringVerboseNegativeComponents(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Days: -1, Hours: -2}
+	assert.Equal(t, "-P0Y0M0W1DT2H0M0S", d.StringVerbose())
+
+	// mixed signs are still printed component by component
+	d = Duration{Days: 1, Hours: -2}
+	assert.Equal(t, "P0Y0M0W1DT-2H0M0S", d.StringVerbose())
+}
+
+func TestStringWeeksOrder(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d        Duration
+		expected string
+	}{
+		{Duration{Weeks: 1, Days: 2}, "P1W2D"},
+		{Duration{Months: 1, Weeks: 2}, "P1M2W"},
+		{Duration{Years: 1, Months: 2, Weeks: 3, Days: 4}, "P1Y2M3W4D"},
+		{Duration{Weeks: 3, Days: 4, Hours: 5}, "P3W4DT5H"},
+		{Duration{Weeks: 1, Days: 10}, "P1W10D"},
+		{Duration{Negative: true, Weeks: 1, Days: 2}, "-P1W2D"},
+	} {
+		assert.Equal(t, tc.expected, tc.d.String())
+
+		// the order is the one FromString expects
+		parsed, err := FromString(tc.expected)
+		assert.NoError(t, err)
+		assert.Equal(t, &tc.d, parsed)
+	}
+
+	// days before weeks are rejected
+	_, err := FromString("P2D1W")
+	assert.ErrorIs(t, err, ErrBadFormat)
+}
+
+func TestFormatWidth(t *testing.T) {
+	t.Parallel()
+
+	d := MustFromString("P01Y")
+	assert.Equal(t, &Duration{Years: 1}, d)
+	assert.Equal(t, "P1Y", d.String())
+
+	opts := FormatOptions{Width: 2}
+	s, err := opts.Format(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "P01Y", s)
+
+	for _, tc := range []struct {
+		opts     FormatOptions
+		d        string
+		expected string
+	}{
+		{FormatOptions{Width: 2}, "P1Y2M3DT4H5M6S", "P01Y02M03DT04H05M06S"},
+		{FormatOptions{Width: 2}, "P100D", "P100D"},
+		{FormatOptions{Width: 3}, "P2W", "P002W"},
+		{FormatOptions{Width: 2}, "PT1.5S", "PT01.5S"},
+		{FormatOptions{Width: 2}, "-PT5M", "-PT05M"},
+		{FormatOptions{Width: 2, ZeroComponents: true}, "P1D", "P00Y00M00W01DT00H00M00S"},
+		{FormatOptions{Width: 1}, "P1D", "P1D"},
+		{FormatOptions{Width: -1}, "P1D", "P1D"},
+	} {
+		s, err := tc.opts.Format(MustFromString(tc.d))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, s, "%s with %+v", tc.d, tc.opts)
+
+		// padded output parses back to the same duration
+		assert.Equal(t, MustFromString(tc.d).canonical(), MustFromString(s).canonical())
+	}
+
+	// best-effort output of mixed signs
+	assert.Equal(t, "P01DT-02H", (&Duration{Days: 1, Hours: -2}).WithOptions(FormatOptions{Width: 2}).String())
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Timeout *Duration `json:"timeout"`
+		Retry   Duration  `json:"retry"`
+	}
+
+	for input, expected := range map[string]config{
+		`{"timeout": "PT30S", "retry": "P1D"}`:   {Timeout: &Duration{Seconds: 30}, Retry: Duration{Days: 1}},
+		`{"timeout": 30, "retry": 86400}`:        {Timeout: &Duration{Seconds: 30}, Retry: Duration{Seconds: 86400}},
+		`{"timeout": 1.5, "retry": -2}`:          {Timeout: &Duration{Seconds: 1, Nanoseconds: 500000000}, Retry: Duration{Negative: true, Seconds: 2}},
+		`{"timeout": 1e3, "retry": "-PT0.25S"}`:  {Timeout: &Duration{Seconds: 1000}, Retry: Duration{Negative: true, Nanoseconds: 250000000}},
+		`{"timeout": null, "retry": 0}`:          {Retry: Duration{}},
+		`{"timeout": "P1Y2M", "retry": 0.00001}`: {Timeout: &Duration{Years: 1, Months: 2}, Retry: Duration{Nanoseconds: 10000}},
+	} {
+		var c config
+		assert.NoError(t, json.Unmarshal([]byte(input), &c), input)
+		assert.Equal(t, expected, c, input)
+	}
+
+	var c config
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"timeout": "asdf"}`), &c), ErrBadFormat)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"timeout": 1e300}`), &c), ErrOutOfRange)
+	assert.Error(t, json.Unmarshal([]byte(`{"timeout": true}`), &c))
+
+	// strings round-trip with MarshalText
+	out, err := json.Marshal(&config{Timeout: &Duration{Minutes: 5}, Retry: Duration{Weeks: 1}})
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(out, &c))
+	assert.Equal(t, config{Timeout: &Duration{Minutes: 5}, Retry: Duration{Weeks: 1}}, c)
+}
+
+func TestFractionRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		s        string
+		expected time.Duration
+		str      string
+	}{
+		// milliseconds
+		{"PT0.001S", time.Millisecond, "PT0.001S"},
+		{"PT0.999S", 999 * time.Millisecond, "PT0.999S"},
+		{"PT1.25S", 1250 * time.Millisecond, "PT1.25S"},
+		{"-PT0.001S", -time.Millisecond, "-PT0.001S"},
+		// microseconds
+		{"PT0.000001S", time.Microsecond, "PT0.000001S"},
+		{"PT0.123456S", 123456 * time.Microsecond, "PT0.123456S"},
+		{"PT1M0.000010S", time.Minute + 10*time.Microsecond, "PT1M0.00001S"},
+		// nanoseconds
+		{"PT0.000000001S", time.Nanosecond, "PT0.000000001S"},
+		{"PT0.999999999S", time.Second - time.Nanosecond, "PT0.999999999S"},
+		{"P1DT0.000000100S", 24*time.Hour + 100*time.Nanosecond, "P1DT0.0000001S"},
+		// trailing zeros are trimmed
+		{"PT0.0010S", time.Millisecond, "PT0.001S"},
+		{"PT1.500S", 1500 * time.Millisecond, "PT1.5S"},
+		{"PT2.000S", 2 * time.Second, "PT2S"},
+		{"PT0,001S", time.Millisecond, "PT0.001S"},
+	} {
+		d, err := FromString(tc.s)
+		if !assert.NoError(t, err, tc.s) {
+			continue
+		}
+		assert.Equal(t, tc.expected, d.ToEstimatedDuration(), tc.s)
+		assert.Equal(t, tc.str, d.String(), tc.s)
+
+		again, err := FromString(d.String())
+		assert.NoError(t, err, tc.s)
+		assert.Equal(t, d, again, tc.s)
+	}
+}
@@ -0,0 +1,55 @@
+package iso8601duration
+
+import (
+	"errors"
+	"fmt"
+	"text/template"
+)
+
+// Funcs returns template functions for working with durations, for
+// use with text/template and html/template:
+//
+//   - isoDuration parses and validates a string into a *Duration
+//   - humanizeDuration renders a duration in plain English
+//   - formatDuration formats a duration, optionally using the
+//     FormatOptions passed as second argument
+//
+// The functions accept a *Duration, a Duration or a string to be
+// parsed. Errors are returned to the template instead of panicking.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"isoDuration": FromString,
+		"humanizeDuration": func(v interface{}) (string, error) {
+			d, err := templateDuration(v)
+			if err != nil {
+				return "", err
+			}
+			return d.Humanize(), nil
+		},
+		"formatDuration": func(v interface{}, opts ...FormatOptions) (string, error) {
+			d, err := templateDuration(v)
+			if err != nil {
+				return "", err
+			}
+			if len(opts) > 1 {
+				return "", errors.New("formatDuration: too many options")
+			}
+			if len(opts) == 1 {
+				return opts[0].Format(d)
+			}
+			return d.Format()
+		},
+	}
+}
+
+func templateDuration(v interface{}) (*Duration, error) {
+	switch v := v.(type) {
+	case *Duration:
+		return v, nil
+	case Duration:
+		return &v, nil
+	case string:
+		return FromString(v)
+	}
+	return nil, fmt.Errorf("unsupported duration type %T", v)
+}
@@ -0,0 +1,45 @@
+package iso8601duration
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncs(t *testing.T) {
+	t.Parallel()
+
+	render := func(text string, data interface{}) (string, error) {
+		tmpl, err := template.New("test").Funcs(Funcs()).Parse(text)
+		if err != nil {
+			return "", err
+		}
+		var s strings.Builder
+		err = tmpl.Execute(&s, data)
+		return s.String(), err
+	}
+
+	s, err := render(`{{ (isoDuration "P2D").Days }}`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "2", s)
+
+	s, err = render(`{{ humanizeDuration . }}`, Duration{Hours: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "1 hour", s)
+
+	s, err = render(`{{ formatDuration "P2W" .}}`, FormatOptions{Weeks: WeeksAsDays})
+	assert.Nil(t, err)
+	assert.Equal(t, "P14D", s)
+
+	// errors are reported instead of panicking
+	_, err = render(`{{ isoDuration "asdf" }}`, nil)
+	assert.ErrorIs(t, err, ErrBadFormat)
+
+	_, err = render(`{{ humanizeDuration 42 }}`, nil)
+	assert.NotNil(t, err)
+
+	_, err = render(`{{ formatDuration . }}`, &Duration{Hours: 1, Minutes: -1})
+	assert.ErrorIs(t, err, ErrMixedSigns)
+}
@@ -2,7 +2,10 @@ module github.com/toowoxx/go-iso8601duration
 
 go 1.17
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/stretchr/testify v1.7.0
+	golang.org/x/text v0.3.7
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
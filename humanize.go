@@ -0,0 +1,231 @@
+package iso8601duration
+
+import (
+	"strconv"
+	"time"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// Humanizer renders durations in plain English, e.g.
+// "1 year, 2 months and 3 hours".
+// The zero value is ready to use and renders negative durations the
+// same way as positive ones.
+type Humanizer struct {
+	// NegativePrefix is prepended to negative durations, e.g. "minus "
+	NegativePrefix string
+	// NegativeSuffix is appended to negative durations, e.g. " ago"
+	NegativeSuffix string
+	// MaxUnits limits the output to the given number of the largest
+	// non-zero units, e.g. "1 year and 2 months" for P1Y2M3DT4H and 2.
+	// Zero means no limit.
+	MaxUnits int
+	// Round rounds the last unit shown to the nearest value, based on
+	// the estimated length of the units that have been left out.
+	// Otherwise they are simply dropped. A unit rounded up to a whole
+	// larger one is carried into it, so PT1H59M45S with two units is
+	// "2 hours".
+	Round bool
+}
+
+// Humanize renders the duration in plain English, e.g.
+// "1 year, 2 months and 3 hours". Zero components are omitted and
+// the zero duration is rendered as "0 seconds". Fractions of seconds
+// are kept, e.g. "1.5 seconds". Negative durations get an " ago"
+// suffix. Use a Humanizer to configure this.
+func (d *Duration) Humanize() string {
+	return Humanizer{NegativeSuffix: " ago"}.Humanize(d)
+}
+
+// HumanizeN works like Humanize, but only renders the n largest
+// non-zero units. The remaining units are dropped.
+func (d *Duration) HumanizeN(n int) string {
+	return Humanizer{NegativeSuffix: " ago", MaxUnits: n}.Humanize(d)
+}
+
+// Humanize renders d in plain English
+func (h Humanizer) Humanize(d *Duration) string {
+	c := catalogEnglish
+	c.NegativePrefix = h.NegativePrefix
+	c.NegativeSuffix = h.NegativeSuffix
+	if h.MaxUnits > 0 {
+		d = d.mostSignificant(h.MaxUnits, h.Round)
+	}
+	return c.humanize(language.English, d)
+}
+
+// TopN returns a copy of the duration keeping only the n largest
+// non-zero components, e.g. P1Y2M for P1Y2M3DT4H and 2. The others are
+// set to zero without rounding, see Humanizer.Round for rounding.
+// Seconds with a fraction count as non-zero and keep their fraction.
+func (d *Duration) TopN(n int) *Duration {
+	c := &Duration{}
+	if d != nil {
+		*c = *d
+	}
+
+	comps := c.components()
+	kept := 0
+	for i, v := range comps {
+		if v == 0 && (i < len(comps)-1 || c.Nanoseconds == 0) {
+			continue
+		}
+		if kept < n {
+			kept++
+			continue
+		}
+		comps[i] = 0
+		if i == len(comps)-1 {
+			c.Nanoseconds = 0
+		}
+	}
+	c.setComponents(comps)
+	if c.IsZero() {
+		c.Negative = false
+	}
+	return c
+}
+
+// mostSignificant returns a copy of the duration keeping only the n
+// largest non-zero components. With round set, the components that
+// are left out round the last one kept to the nearest value, based on
+// their estimated lengths.
+func (d *Duration) mostSignificant(n int, round bool) *Duration {
+	c := &Duration{}
+	if d != nil {
+		*c = *d
+	}
+
+	comps := c.components()
+	last := -1
+	for i, kept := 0, 0; i < len(comps) && kept < n; i++ {
+		if comps[i] != 0 {
+			last = i
+			kept++
+		}
+	}
+	if last < 0 {
+		return c
+	}
+
+	rem := time.Duration(c.Nanoseconds)
+	c.Nanoseconds = 0
+	for i := last + 1; i < len(comps); i++ {
+		rem += time.Duration(comps[i]) * estimatedLengths[i]
+		comps[i] = 0
+	}
+	if round {
+		step := 0
+		if rem*2 >= estimatedLengths[last] {
+			step = 1
+		} else if rem*2 <= -estimatedLengths[last] {
+			step = -1
+		}
+		comps[last] += step
+
+		// a unit rounded up to a whole larger one is carried into it,
+		// e.g. 60 minutes into an hour
+		for i := last; step != 0 && i > 0 && carries[i-1] != 0 && comps[i] == step*carries[i-1]; i-- {
+			comps[i-1] += step
+			comps[i] = 0
+		}
+	}
+	c.setComponents(comps)
+
+	return c
+}
+
+func (c *Catalog) humanize(tag language.Tag, d *Duration) string {
+	if d == nil {
+		d = &Duration{}
+	}
+	comps := d.components()
+
+	// seconds with a fraction count as non-zero, e.g. in PT0.5S
+	last := len(comps) - 1
+	nanos := d.Nanoseconds
+	if s, ok := addInt(comps[last], nanos/1e9); ok {
+		comps[last], nanos = shareSign(s, nanos%1e9)
+	}
+	nonZero := func(i int) bool {
+		return comps[i] != 0 || (i == last && nanos != 0)
+	}
+
+	count := 0
+	for i := range comps {
+		if nonZero(i) {
+			count++
+		}
+	}
+
+	b := make([]byte, 0, 64)
+	if d.Negative {
+		b = append(b, c.NegativePrefix...)
+	}
+	if count == 0 {
+		b = c.appendUnit(b, tag, last, 0, 0)
+	}
+	written := 0
+	for i, v := range comps {
+		if !nonZero(i) {
+			continue
+		}
+		if written > 0 {
+			if written == count-1 {
+				b = append(b, c.LastSeparator...)
+			} else {
+				b = append(b, c.Separator...)
+			}
+		}
+		if i == last {
+			b = c.appendUnit(b, tag, i, v, nanos)
+		} else {
+			b = c.appendUnit(b, tag, i, v, 0)
+		}
+		written++
+	}
+	if d.Negative {
+		b = append(b, c.NegativeSuffix...)
+	}
+
+	return string(b)
+}
+
+// appendUnit appends val with the fraction given in nanoseconds, which
+// must share the sign of val, and the name of the unit
+func (c *Catalog) appendUnit(b []byte, tag language.Tag, unit int, val, nanos int) []byte {
+	if val < 0 || nanos < 0 {
+		b = append(b, '-')
+		val, nanos = -val, -nanos
+	}
+
+	// the fraction is printed without trailing zeros and selects the
+	// plural form along with the integer part, e.g. 1.5 is plural
+	frac, digits := nanos, 0
+	if frac != 0 {
+		digits = 9
+		for frac%10 == 0 {
+			frac /= 10
+			digits--
+		}
+	}
+	form := plural.Cardinal.MatchPlural(tag, val, digits, digits, frac, frac)
+
+	name, ok := c.Units[unit][form]
+	if !ok {
+		name = c.Units[unit][plural.Other]
+	}
+
+	b = strconv.AppendInt(b, int64(val), 10)
+	if digits > 0 {
+		if c.DecimalSeparator == "" {
+			b = append(b, '.')
+		} else {
+			b = append(b, c.DecimalSeparator...)
+		}
+		b = appendPadded(b, frac, digits)
+	}
+	b = append(b, c.NumberSeparator...)
+	return append(b, name...)
+}
@@ -0,0 +1,152 @@
+package iso8601duration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d    Duration
+		want string
+	}{
+		{Duration{}, "0 seconds"},
+		{Duration{Seconds: 1}, "1 second"},
+		{Duration{Seconds: 2}, "2 seconds"},
+		{Duration{Years: 1}, "1 year"},
+		{Duration{Years: 2}, "2 years"},
+		{Duration{Months: 1, Days: 1}, "1 month and 1 day"},
+		{Duration{Months: 2, Days: 2}, "2 months and 2 days"},
+		{Duration{Weeks: 1, Hours: 1, Minutes: 1}, "1 week, 1 hour and 1 minute"},
+		{Duration{Years: 1, Months: 2, Hours: 3}, "1 year, 2 months and 3 hours"},
+		{
+			Duration{Years: 1, Months: 2, Weeks: 3, Days: 4, Hours: 5, Minutes: 6, Seconds: 7},
+			"1 year, 2 months, 3 weeks, 4 days, 5 hours, 6 minutes and 7 seconds",
+		},
+		{Duration{Negative: true, Days: 3}, "3 days ago"},
+		{Duration{Negative: true}, "0 seconds ago"},
+
+		// fractions of seconds
+		{Duration{Nanoseconds: 500000000}, "0.5 seconds"},
+		{Duration{Seconds: 1, Nanoseconds: 500000000}, "1.5 seconds"},
+		{Duration{Minutes: 1, Nanoseconds: 250000000}, "1 minute and 0.25 seconds"},
+		{Duration{Seconds: 2, Nanoseconds: -500000000}, "1.5 seconds"},
+		{Duration{Negative: true, Seconds: 1, Nanoseconds: 5}, "1.000000005 seconds ago"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.d.Humanize())
+	}
+}
+
+func TestHumanizer(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Negative: true, Hours: 1, Minutes: 30}
+
+	h := Humanizer{NegativePrefix: "minus "}
+	assert.Equal(t, "minus 1 hour and 30 minutes", h.Humanize(&d))
+
+	h = Humanizer{NegativePrefix: "(", NegativeSuffix: ")"}
+	assert.Equal(t, "(1 hour and 30 minutes)", h.Humanize(&d))
+
+	// the zero value ignores the sign
+	assert.Equal(t, "1 hour and 30 minutes", Humanizer{}.Humanize(&d))
+
+	// positive durations are not decorated
+	d.Negative = false
+	assert.Equal(t, "1 hour and 30 minutes", h.Humanize(&d))
+}
+
+func TestHumanizeN(t *testing.T) {
+	t.Parallel()
+
+	d := Duration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6}
+
+	assert.Equal(t, "1 year and 2 months", d.HumanizeN(2))
+	assert.Equal(t, "1 year", d.HumanizeN(1))
+	assert.Equal(t, "1 year, 2 months and 3 days", d.HumanizeN(3))
+
+	// n larger than the number of non-zero units
+	assert.Equal(t, "1 year, 2 months, 3 days, 4 hours, 5 minutes and 6 seconds", d.HumanizeN(10))
+	assert.Equal(t, d.Humanize(), d.HumanizeN(0))
+
+	// the only non-zero unit is always shown
+	assert.Equal(t, "6 seconds", (&Duration{Seconds: 6}).HumanizeN(1))
+	assert.Equal(t, "3 days", (&Duration{Days: 3}).HumanizeN(2))
+	assert.Equal(t, "0 seconds", (&Duration{}).HumanizeN(1))
+
+	// zero units in between do not count
+	assert.Equal(t, "1 year and 5 minutes", (&Duration{Years: 1, Minutes: 5, Seconds: 1}).HumanizeN(2))
+
+	assert.Equal(t, "2 days ago", (&Duration{Negative: true, Days: 2, Hours: 20}).HumanizeN(1))
+}
+
+func TestHumanizerRound(t *testing.T) {
+	t.Parallel()
+
+	h := Humanizer{MaxUnits: 2, Round: true}
+
+	// the remainder is less than half a month
+	assert.Equal(t, "1 year and 2 months", h.Humanize(&Duration{Years: 1, Months: 2, Days: 3, Hours: 4}))
+
+	// the remainder is at least half a month
+	assert.Equal(t, "1 year and 3 months", h.Humanize(&Duration{Years: 1, Months: 2, Days: 15}))
+	assert.Equal(t, "1 year and 3 months", h.Humanize(&Duration{Years: 1, Months: 2, Weeks: 2, Days: 1}))
+
+	// only the largest unit is kept
+	h.MaxUnits = 1
+	assert.Equal(t, "2 hours", h.Humanize(&Duration{Hours: 1, Minutes: 30}))
+	assert.Equal(t, "1 hour", h.Humanize(&Duration{Hours: 1, Minutes: 29, Seconds: 59}))
+	assert.Equal(t, "2 seconds", h.Humanize(&Duration{Seconds: 1, Nanoseconds: 500000000}))
+
+	// rounding up carries into the larger units
+	h.MaxUnits = 2
+	assert.Equal(t, "2 hours", h.Humanize(&Duration{Hours: 1, Minutes: 59, Seconds: 45}))
+	assert.Equal(t, "2 days", h.Humanize(&Duration{Days: 1, Hours: 23, Minutes: 45}))
+	assert.Equal(t, "1 week and 1 day", h.Humanize(&Duration{Weeks: 1, Hours: 23, Minutes: 45}))
+	assert.Equal(t, "2 years", h.Humanize(&Duration{Years: 1, Months: 11, Days: 20}))
+	assert.Equal(t, "1 hour", h.Humanize(&Duration{Minutes: 59, Seconds: 59, Nanoseconds: 600000000}))
+	assert.Equal(t, "2 hours ago", Humanizer{MaxUnits: 2, Round: true, NegativeSuffix: " ago"}.Humanize(&Duration{Negative: true, Hours: 1, Minutes: 59, Seconds: 45}))
+	assert.Equal(t, &Duration{Hours: -2}, (&Duration{Hours: -1, Minutes: -59, Seconds: -45}).mostSignificant(2, true))
+	assert.Equal(t, "1 month and 30 days", h.Humanize(&Duration{Months: 1, Days: 29, Hours: 23}))
+	h.MaxUnits = 1
+
+	// without rounding, the remainder is dropped
+	h.Round = false
+	assert.Equal(t, "1 hour", h.Humanize(&Duration{Hours: 1, Minutes: 59}))
+}
+
+func TestTopN(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d        string
+		n        int
+		expected string
+	}{
+		{"P1Y2M3DT4H", 2, "P1Y2M"},
+		{"P1Y2M3DT4H", 1, "P1Y"},
+		{"P1Y2M3DT4H", 3, "P1Y2M3D"},
+		{"P1Y2M3DT4H", 4, "P1Y2M3DT4H"},
+		{"P1Y2M3DT4H", 10, "P1Y2M3DT4H"},
+		{"P1YT4H5S", 2, "P1YT4H"},
+		{"P3DT59M59S", 1, "P3D"},
+		{"PT1M30.5S", 2, "PT1M30.5S"},
+		{"PT1M0.5S", 2, "PT1M0.5S"},
+		{"PT1M30.5S", 1, "PT1M"},
+		{"PT0.5S", 1, "PT0.5S"},
+		{"-P1DT2H3M", 2, "-P1DT2H"},
+		{"P1Y2M", 0, "PT0S"},
+		{"PT0S", 2, "PT0S"},
+	} {
+		d := MustFromString(tc.d)
+		assert.Equal(t, tc.expected, d.TopN(tc.n).String(), "%s top %d", tc.d, tc.n)
+		assert.Equal(t, tc.d, d.String(), "%s: input modified", tc.d)
+	}
+
+	assert.Equal(t, &Duration{}, (*Duration)(nil).TopN(2))
+}
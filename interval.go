@@ -0,0 +1,64 @@
+package iso8601duration
+
+import (
+	"math"
+	"time"
+)
+
+// ToMonthsDaysNanos converts the duration into months, days and
+// nanoseconds, the representation of intervals used by PostgreSQL and
+// Apache Arrow among others. Years are counted as twelve months, weeks
+// as seven days and the time part is converted into nanoseconds, so
+// nothing is estimated. Each part keeps its own sign, e.g. 1, -2 and
+// 3600000000000 for Duration{Months: 1, Days: -2, Hours: 1}.
+//
+// ErrOutOfRange is returned if a part overflows an int64.
+func (d *Duration) ToMonthsDaysNanos() (months, days, nanos int64, err error) {
+	s := d.signed()
+
+	m, ok1 := mulInt(s.Years, 12)
+	m, ok2 := addInt(m, s.Months)
+	w, ok3 := mulInt(s.Weeks, 7)
+	dd, ok4 := addInt(w, s.Days)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return 0, 0, 0, ErrOutOfRange
+	}
+
+	n := s.Nanoseconds
+	for _, part := range []struct {
+		v    int
+		unit time.Duration
+	}{{s.Hours, time.Hour}, {s.Minutes, time.Minute}, {s.Seconds, time.Second}} {
+		p, ok := mulInt(part.v, int(part.unit))
+		if !ok {
+			return 0, 0, 0, ErrOutOfRange
+		}
+		if n, ok = addInt(n, p); !ok {
+			return 0, 0, 0, ErrOutOfRange
+		}
+	}
+	return int64(m), int64(dd), int64(n), nil
+}
+
+// FromMonthsDaysNanos reverses ToMonthsDaysNanos. Months and days are
+// kept as they are, as carrying them into years and weeks would change
+// the textual representation only, while nanos are split into hours,
+// minutes, seconds and nanoseconds like by FromTimeDuration, e.g.
+// P14M3DT1H30M for 14, 3 and 5400000000000. Parts of different signs
+// result in mixed signs, see Validate.
+func FromMonthsDaysNanos(months, days, nanos int64) *Duration {
+	t := FromTimeDuration(time.Duration(nanos)).signed()
+	res := &Duration{
+		Months:      int(months),
+		Days:        int(days),
+		Hours:       t.Hours,
+		Minutes:     t.Minutes,
+		Seconds:     t.Seconds,
+		Nanoseconds: t.Nanoseconds,
+	}
+	// the Negative flag can not hold the magnitude of math.MinInt64
+	if res.hasMixedSigns() || months == math.MinInt64 || days == math.MinInt64 {
+		return res
+	}
+	return res.canonical()
+}
@@ -0,0 +1,76 @@
+package iso8601duration
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthsDaysNanos(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d                   string
+		months, days, nanos int64
+		roundTrip           string
+	}{
+		{"P1Y2M3W4DT5H6M7.5S", 14, 25, int64(5*time.Hour + 6*time.Minute + 7500*time.Millisecond), "P14M25DT5H6M7.5S"},
+		{"P1M", 1, 0, 0, "P1M"},
+		{"P2W", 0, 14, 0, "P14D"},
+		{"PT36H", 0, 0, int64(36 * time.Hour), "PT36H"},
+		{"PT0.000000001S", 0, 0, 1, "PT0.000000001S"},
+		{"-P1Y1DT1H", -12, -1, -int64(time.Hour), "-P12M1DT1H"},
+		{"PT0S", 0, 0, 0, "PT0S"},
+	} {
+		d := MustFromString(tc.d)
+		months, days, nanos, err := d.ToMonthsDaysNanos()
+		assert.NoError(t, err, tc.d)
+		assert.Equal(t, tc.months, months, tc.d)
+		assert.Equal(t, tc.days, days, tc.d)
+		assert.Equal(t, tc.nanos, nanos, tc.d)
+
+		back := FromMonthsDaysNanos(months, days, nanos)
+		assert.Equal(t, tc.roundTrip, back.String(), tc.d)
+		from := time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)
+		assert.True(t, d.AddTo(from).Equal(back.AddTo(from)), tc.d)
+
+		// converting again yields the same triple
+		m2, d2, n2, err := back.ToMonthsDaysNanos()
+		assert.NoError(t, err, tc.d)
+		assert.Equal(t, []int64{months, days, nanos}, []int64{m2, d2, n2}, tc.d)
+	}
+
+	// parts of different signs are kept
+	d := &Duration{Months: 1, Days: -2, Hours: 1}
+	months, days, nanos, err := d.ToMonthsDaysNanos()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, -2, int64(time.Hour)}, []int64{months, days, nanos})
+	assert.Equal(t, d, FromMonthsDaysNanos(1, -2, int64(time.Hour)))
+
+	assert.Equal(t, &Duration{Months: math.MinInt64, Days: -1}, FromMonthsDaysNanos(math.MinInt64, -1, 0))
+	assert.Equal(t, "PT2562047H47M16.854775807S", FromMonthsDaysNanos(0, 0, math.MaxInt64).String())
+
+	months, days, nanos, err = (*Duration)(nil).ToMonthsDaysNanos()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0, 0, 0}, []int64{months, days, nanos})
+}
+
+func TestToMonthsDaysNanosOverflow(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []*Duration{
+		{Years: math.MaxInt64 / 10},
+		{Years: 1, Months: math.MaxInt64},
+		{Weeks: math.MaxInt64 / 2},
+		{Days: 1, Weeks: math.MaxInt64 / 7},
+		{Hours: 2562048},
+		{Hours: 2562047, Minutes: 48},
+		{Seconds: math.MaxInt64},
+		{Negative: true, Hours: 2562048},
+	} {
+		_, _, _, err := d.ToMonthsDaysNanos()
+		assert.ErrorIs(t, err, ErrOutOfRange, "%+v", d)
+	}
+}
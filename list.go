@@ -0,0 +1,41 @@
+package iso8601duration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ListError is returned by ParseMany when an element of the list
+// could not be parsed
+type ListError struct {
+	// Index is the position of the failing element in the list
+	Index int
+	Err   error
+}
+
+func (e *ListError) Error() string {
+	return fmt.Sprintf("element %d: %s", e.Index, e.Err)
+}
+
+func (e *ListError) Unwrap() error {
+	return e.Err
+}
+
+// ParseMany parses a list of durations separated by sep, e.g.
+// "P1D,PT2H,P1W". Whitespace around the elements is ignored.
+// If an element fails to parse, a *ListError holding its index is
+// returned.
+func ParseMany(s string, sep string) ([]*Duration, error) {
+	parts := strings.Split(s, sep)
+	durations := make([]*Duration, 0, len(parts))
+
+	for i, part := range parts {
+		d, err := FromString(strings.TrimSpace(part))
+		if err != nil {
+			return nil, &ListError{Index: i, Err: err}
+		}
+		durations = append(durations, d)
+	}
+
+	return durations, nil
+}
@@ -0,0 +1,30 @@
+package iso8601duration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMany(t *testing.T) {
+	t.Parallel()
+
+	ds, err := ParseMany("P1D,PT2H, P1W", ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []*Duration{{Days: 1}, {Hours: 2}, {Weeks: 1}}, ds)
+
+	ds, err = ParseMany("P1D\nPT2H", "\n")
+	assert.Nil(t, err)
+	assert.Equal(t, []*Duration{{Days: 1}, {Hours: 2}}, ds)
+
+	// bad element in the middle
+	ds, err = ParseMany("P1D,asdf,P1W", ",")
+	assert.Nil(t, ds)
+	assert.True(t, errors.Is(err, ErrBadFormat))
+
+	var listErr *ListError
+	assert.True(t, errors.As(err, &listErr))
+	assert.Equal(t, 1, listErr.Index)
+	assert.Equal(t, "element 1: bad format string", err.Error())
+}
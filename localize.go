@@ -0,0 +1,235 @@
+package iso8601duration
+
+import (
+	"sync"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// UnitNames maps plural forms to the name of a unit. Forms which are
+// missing fall back to plural.Other.
+type UnitNames map[plural.Form]string
+
+// Catalog holds everything needed to humanize durations in a language
+type Catalog struct {
+	// Units holds the unit names ordered from years to seconds
+	Units [7]UnitNames
+	// NumberSeparator goes between a number and its unit name
+	NumberSeparator string
+	// DecimalSeparator goes before the fraction of seconds, e.g. in
+	// 1.5 seconds. A point is used if it is empty.
+	DecimalSeparator string
+	// Separator joins the components, except for the last two
+	Separator string
+	// LastSeparator joins the last two components
+	LastSeparator string
+	// NegativePrefix is prepended to negative durations
+	NegativePrefix string
+	// NegativeSuffix is appended to negative durations
+	NegativeSuffix string
+}
+
+// Localizer humanizes durations in different languages. Plural forms
+// are selected according to the CLDR plural rules of the language.
+// It is safe for concurrent use.
+type Localizer struct {
+	mu       sync.RWMutex
+	catalogs map[language.Tag]*Catalog
+}
+
+// NewLocalizer returns a Localizer with the built-in catalogs for
+// English, German, French, Spanish, Polish, Russian and Japanese
+func NewLocalizer() *Localizer {
+	l := &Localizer{catalogs: map[language.Tag]*Catalog{}}
+	for tag, c := range builtinCatalogs {
+		c := c
+		l.Register(tag, &c)
+	}
+	return l
+}
+
+// defaultLocalizer is used by Duration.HumanizeIn
+var defaultLocalizer = NewLocalizer()
+
+// Register adds or replaces the catalog for a language
+func (l *Localizer) Register(tag language.Tag, c *Catalog) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.catalogs[tag] = c
+}
+
+// RegisterCatalog adds or replaces the catalog for a language in the
+// catalogs used by Duration.HumanizeIn
+func RegisterCatalog(tag language.Tag, c *Catalog) {
+	defaultLocalizer.Register(tag, c)
+}
+
+// Humanize renders d in the language given by tag. If there is no
+// catalog for tag, its parent languages are tried, e.g. de for de-CH.
+// English is used as the last resort.
+func (l *Localizer) Humanize(tag language.Tag, d *Duration) string {
+	return l.catalog(tag).humanize(tag, d)
+}
+
+// HumanizeIn renders the duration in the language given by tag using
+// the built-in and registered catalogs. See Localizer.Humanize.
+func (d *Duration) HumanizeIn(tag language.Tag) string {
+	return defaultLocalizer.Humanize(tag, d)
+}
+
+// HumanizeLocale renders the duration with the given unit names, e.g.
+// "1 Jahr, 2 Monate, 3 Stunden" for P1Y2MT3H with German names. The
+// keys of unitNames are the units accepted by Get, from "years" to
+// "seconds", and the values hold the singular and plural name. The
+// singular is used for a count of one. Units which are missing keep
+// their English names.
+//
+// As the language is not known, the components are joined with commas
+// and negative durations get a leading minus sign. Register a Catalog
+// and use HumanizeIn for full control.
+func (d *Duration) HumanizeLocale(unitNames map[string][2]string) string {
+	c := Catalog{
+		Units:           catalogEnglish.Units,
+		NumberSeparator: " ",
+		Separator:       ", ",
+		LastSeparator:   ", ",
+		NegativePrefix:  "-",
+	}
+	for i, unit := range []string{"years", "months", "weeks", "days", "hours", "minutes", "seconds"} {
+		if names, ok := unitNames[unit]; ok {
+			c.Units[i] = UnitNames{plural.One: names[0], plural.Other: names[1]}
+		}
+	}
+	return c.humanize(language.English, d)
+}
+
+func (l *Localizer) catalog(tag language.Tag) *Catalog {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for t := tag; ; t = t.Parent() {
+		if c, ok := l.catalogs[t]; ok {
+			return c
+		}
+		if t.IsRoot() {
+			break
+		}
+	}
+	if c, ok := l.catalogs[language.English]; ok {
+		return c
+	}
+	return &catalogEnglish
+}
+
+var catalogEnglish = Catalog{
+	Units: [7]UnitNames{
+		{plural.One: "year", plural.Other: "years"},
+		{plural.One: "month", plural.Other: "months"},
+		{plural.One: "week", plural.Other: "weeks"},
+		{plural.One: "day", plural.Other: "days"},
+		{plural.One: "hour", plural.Other: "hours"},
+		{plural.One: "minute", plural.Other: "minutes"},
+		{plural.One: "second", plural.Other: "seconds"},
+	},
+	NumberSeparator: " ",
+	Separator:       ", ",
+	LastSeparator:   " and ",
+	NegativeSuffix:  " ago",
+}
+
+var builtinCatalogs = map[language.Tag]Catalog{
+	language.English: catalogEnglish,
+	language.German: {
+		Units: [7]UnitNames{
+			{plural.One: "Jahr", plural.Other: "Jahre"},
+			{plural.One: "Monat", plural.Other: "Monate"},
+			{plural.One: "Woche", plural.Other: "Wochen"},
+			{plural.One: "Tag", plural.Other: "Tage"},
+			{plural.One: "Stunde", plural.Other: "Stunden"},
+			{plural.One: "Minute", plural.Other: "Minuten"},
+			{plural.One: "Sekunde", plural.Other: "Sekunden"},
+		},
+		NumberSeparator:  " ",
+		DecimalSeparator: ",",
+		Separator:        ", ",
+		LastSeparator:    " und ",
+		NegativePrefix:   "minus ",
+	},
+	language.French: {
+		Units: [7]UnitNames{
+			{plural.One: "an", plural.Other: "ans"},
+			{plural.One: "mois", plural.Other: "mois"},
+			{plural.One: "semaine", plural.Other: "semaines"},
+			{plural.One: "jour", plural.Other: "jours"},
+			{plural.One: "heure", plural.Other: "heures"},
+			{plural.One: "minute", plural.Other: "minutes"},
+			{plural.One: "seconde", plural.Other: "secondes"},
+		},
+		NumberSeparator:  " ",
+		DecimalSeparator: ",",
+		Separator:        ", ",
+		LastSeparator:    " et ",
+		NegativePrefix:   "il y a ",
+	},
+	language.Spanish: {
+		Units: [7]UnitNames{
+			{plural.One: "año", plural.Other: "años"},
+			{plural.One: "mes", plural.Other: "meses"},
+			{plural.One: "semana", plural.Other: "semanas"},
+			{plural.One: "día", plural.Other: "días"},
+			{plural.One: "hora", plural.Other: "horas"},
+			{plural.One: "minuto", plural.Other: "minutos"},
+			{plural.One: "segundo", plural.Other: "segundos"},
+		},
+		NumberSeparator:  " ",
+		DecimalSeparator: ",",
+		Separator:        ", ",
+		LastSeparator:    " y ",
+		NegativePrefix:   "hace ",
+	},
+	language.Polish: {
+		Units: [7]UnitNames{
+			{plural.One: "rok", plural.Few: "lata", plural.Many: "lat", plural.Other: "roku"},
+			{plural.One: "miesiąc", plural.Few: "miesiące", plural.Many: "miesięcy", plural.Other: "miesiąca"},
+			{plural.One: "tydzień", plural.Few: "tygodnie", plural.Many: "tygodni", plural.Other: "tygodnia"},
+			{plural.One: "dzień", plural.Few: "dni", plural.Many: "dni", plural.Other: "dnia"},
+			{plural.One: "godzina", plural.Few: "godziny", plural.Many: "godzin", plural.Other: "godziny"},
+			{plural.One: "minuta", plural.Few: "minuty", plural.Many: "minut", plural.Other: "minuty"},
+			{plural.One: "sekunda", plural.Few: "sekundy", plural.Many: "sekund", plural.Other: "sekundy"},
+		},
+		NumberSeparator:  " ",
+		DecimalSeparator: ",",
+		Separator:        ", ",
+		LastSeparator:    " i ",
+		NegativeSuffix:   " temu",
+	},
+	language.Russian: {
+		Units: [7]UnitNames{
+			{plural.One: "год", plural.Few: "года", plural.Many: "лет", plural.Other: "года"},
+			{plural.One: "месяц", plural.Few: "месяца", plural.Many: "месяцев", plural.Other: "месяца"},
+			{plural.One: "неделя", plural.Few: "недели", plural.Many: "недель", plural.Other: "недели"},
+			{plural.One: "день", plural.Few: "дня", plural.Many: "дней", plural.Other: "дня"},
+			{plural.One: "час", plural.Few: "часа", plural.Many: "часов", plural.Other: "часа"},
+			{plural.One: "минута", plural.Few: "минуты", plural.Many: "минут", plural.Other: "минуты"},
+			{plural.One: "секунда", plural.Few: "секунды", plural.Many: "секунд", plural.Other: "секунды"},
+		},
+		NumberSeparator:  " ",
+		DecimalSeparator: ",",
+		Separator:        ", ",
+		LastSeparator:    " и ",
+		NegativeSuffix:   " назад",
+	},
+	language.Japanese: {
+		Units: [7]UnitNames{
+			{plural.Other: "年"},
+			{plural.Other: "か月"},
+			{plural.Other: "週間"},
+			{plural.Other: "日"},
+			{plural.Other: "時間"},
+			{plural.Other: "分"},
+			{plural.Other: "秒"},
+		},
+		NegativeSuffix: "前",
+	},
+}
@@ -0,0 +1,137 @@
+package iso8601duration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+func TestHumanizeIn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tag  language.Tag
+		d    Duration
+		want string
+	}{
+		{language.English, Duration{Years: 1, Months: 2, Hours: 3}, "1 year, 2 months and 3 hours"},
+		{language.English, Duration{Negative: true, Days: 1}, "1 day ago"},
+
+		{language.German, Duration{Years: 1, Months: 2, Hours: 3}, "1 Jahr, 2 Monate und 3 Stunden"},
+		{language.German, Duration{}, "0 Sekunden"},
+		{language.German, Duration{Negative: true, Minutes: 1}, "minus 1 Minute"},
+		{language.German, Duration{Seconds: 1, Nanoseconds: 500000000}, "1,5 Sekunden"},
+
+		{language.French, Duration{Years: 1, Months: 2, Hours: 3}, "1 an, 2 mois et 3 heures"},
+		{language.French, Duration{}, "0 seconde"},
+		{language.French, Duration{Negative: true, Days: 2}, "il y a 2 jours"},
+		{language.French, Duration{Seconds: 1, Nanoseconds: 500000000}, "1,5 seconde"},
+
+		{language.Spanish, Duration{Years: 2, Days: 1}, "2 años y 1 día"},
+		{language.Spanish, Duration{Negative: true, Hours: 5}, "hace 5 horas"},
+
+		{language.Polish, Duration{Years: 1}, "1 rok"},
+		{language.Polish, Duration{Years: 2}, "2 lata"},
+		{language.Polish, Duration{Years: 5}, "5 lat"},
+		{language.Polish, Duration{Years: 12}, "12 lat"},
+		{language.Polish, Duration{Years: 22}, "22 lata"},
+		{language.Polish, Duration{Days: 3, Hours: 1}, "3 dni i 1 godzina"},
+
+		{language.Russian, Duration{Years: 1}, "1 год"},
+		{language.Russian, Duration{Years: 3}, "3 года"},
+		{language.Russian, Duration{Years: 5}, "5 лет"},
+		{language.Russian, Duration{Years: 11}, "11 лет"},
+		{language.Russian, Duration{Years: 21}, "21 год"},
+		{language.Russian, Duration{Negative: true, Weeks: 2, Days: 5}, "2 недели и 5 дней назад"},
+		{language.Russian, Duration{Seconds: 1, Nanoseconds: 500000000}, "1,5 секунды"},
+
+		{language.Japanese, Duration{Years: 1, Months: 2, Hours: 3}, "1年2か月3時間"},
+		{language.Japanese, Duration{Negative: true, Days: 3}, "3日前"},
+		{language.Japanese, Duration{Nanoseconds: 500000000}, "0.5秒"},
+
+		// regional variants fall back to their language
+		{language.MustParse("de-CH"), Duration{Days: 2}, "2 Tage"},
+		{language.MustParse("fr-CA"), Duration{Days: 2}, "2 jours"},
+
+		// unknown languages fall back to English
+		{language.MustParse("pt-BR"), Duration{Days: 2}, "2 days"},
+		{language.Und, Duration{Days: 2}, "2 days"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.d.HumanizeIn(test.tag), test.tag.String())
+	}
+}
+
+func TestLocalizerRegister(t *testing.T) {
+	t.Parallel()
+
+	l := NewLocalizer()
+	l.Register(language.Dutch, &Catalog{
+		Units: [7]UnitNames{
+			{plural.One: "jaar", plural.Other: "jaar"},
+			{plural.One: "maand", plural.Other: "maanden"},
+			{plural.One: "week", plural.Other: "weken"},
+			{plural.One: "dag", plural.Other: "dagen"},
+			{plural.One: "uur", plural.Other: "uur"},
+			{plural.One: "minuut", plural.Other: "minuten"},
+			{plural.One: "seconde", plural.Other: "seconden"},
+		},
+		NumberSeparator: " ",
+		Separator:       ", ",
+		LastSeparator:   " en ",
+		NegativeSuffix:  " geleden",
+	})
+
+	d := Duration{Days: 2, Hours: 1}
+	assert.Equal(t, "2 dagen en 1 uur", l.Humanize(language.Dutch, &d))
+
+	// other localizers are not affected
+	assert.Equal(t, "2 days and 1 hour", NewLocalizer().Humanize(language.Dutch, &d))
+	assert.Equal(t, "2 days and 1 hour", d.HumanizeIn(language.Dutch))
+}
+
+func TestHumanizeLocale(t *testing.T) {
+	t.Parallel()
+
+	german := map[string][2]string{
+		"years":   {"Jahr", "Jahre"},
+		"months":  {"Monat", "Monate"},
+		"weeks":   {"Woche", "Wochen"},
+		"days":    {"Tag", "Tage"},
+		"hours":   {"Stunde", "Stunden"},
+		"minutes": {"Minute", "Minuten"},
+		"seconds": {"Sekunde", "Sekunden"},
+	}
+	french := map[string][2]string{
+		"years": {"an", "ans"},
+		"days":  {"jour", "jours"},
+		"hours": {"heure", "heures"},
+	}
+
+	for _, tc := range []struct {
+		d        string
+		names    map[string][2]string
+		expected string
+	}{
+		{"P1Y2MT3H", german, "1 Jahr, 2 Monate, 3 Stunden"},
+		{"P2W1D", german, "2 Wochen, 1 Tag"},
+		{"PT1M1S", german, "1 Minute, 1 Sekunde"},
+		{"-P3D", german, "-3 Tage"},
+		{"PT0S", german, "0 Sekunden"},
+		{"PT0.5S", german, "0.5 Sekunden"},
+		{"PT1.5S", german, "1.5 Sekunden"},
+		{"-PT1M1.5S", german, "-1 Minute, 1.5 Sekunden"},
+		{"P1Y3D", french, "1 an, 3 jours"},
+		// missing units keep their English names
+		{"P2Y1MT1H", french, "2 ans, 1 month, 1 heure"},
+		{"P1D", nil, "1 day"},
+	} {
+		d := MustFromString(tc.d)
+		assert.Equal(t, tc.expected, d.HumanizeLocale(tc.names), tc.d)
+	}
+
+	assert.Equal(t, "0 Sekunden", (*Duration)(nil).HumanizeLocale(german))
+}
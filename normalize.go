@@ -0,0 +1,141 @@
+package iso8601duration
+
+import "time"
+
+// carries holds how many of the next smaller unit make up one of a
+// component, ordered like components followed by nanoseconds, e.g. 24
+// hours in a day. It is zero where carrying is not exact, that is
+// between months and weeks.
+var carries = [8]int{12, 0, 7, 24, 60, 60, 1e9, 0}
+
+// Normalize returns a copy of the duration with overflowing time
+// components carried into the next larger unit, e.g. PT2H11M15S for
+// PT130M75S. Carrying stops at hours, see NormalizeOpts to carry into
+// days and weeks. Years, months, weeks and days are never touched.
+//
+// Components of different signs are borrowed through, so PT1H-30M
+// becomes PT30M. The result is negative if the carried components add
+// up to a negative duration.
+func (d *Duration) Normalize() *Duration {
+	return d.NormalizeOpts(SplitOptions{})
+}
+
+// NormalizeOpts works like Normalize, but can carry hours into days and
+// days into weeks, assuming days of 24 hours. Years and months are
+// never touched, as their length depends on the calendar.
+func (d *Duration) NormalizeOpts(opts SplitOptions) *Duration {
+	if d == nil {
+		return nil
+	}
+
+	// the largest component carried into
+	top := 4
+	if opts.Weeks {
+		top = 2
+	} else if opts.Days {
+		top = 3
+	}
+
+	s := d.signed()
+	comps := s.components()
+
+	// carry the overflow up, truncating towards zero
+	comps[6] += s.Nanoseconds / 1e9
+	nanos := s.Nanoseconds % 1e9
+	for i := 6; i > top; i-- {
+		comps[i-1] += comps[i] / carries[i-1]
+		comps[i] %= carries[i-1]
+	}
+
+	// the largest non-zero component now determines the sign, borrow
+	// from the larger components to make the smaller ones agree
+	sign := 0
+	for i := top; i <= 6 && sign == 0; i++ {
+		sign = signum(comps[i])
+	}
+	if sign == 0 {
+		sign = signum(nanos)
+	}
+	if signum(nanos) == -sign {
+		nanos += sign * 1e9
+		comps[6] -= sign
+	}
+	for i := 6; i > top; i-- {
+		if signum(comps[i]) == -sign {
+			comps[i] += sign * carries[i-1]
+			comps[i-1] -= sign
+		}
+	}
+
+	res := &Duration{Nanoseconds: nanos}
+	res.setComponents(comps)
+	if res.hasMixedSigns() {
+		return res
+	}
+	return res.canonical()
+}
+
+func signum(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// EqualNormalized reports whether both durations are equal after
+// Normalize, e.g. PT60S and PT1M. As Normalize stops at hours, PT24H
+// and P1D are not equal. Zero durations are equal regardless of their
+// sign.
+func (d *Duration) EqualNormalized(other *Duration) bool {
+	opts := SplitOptions{}
+	return *d.normalized(opts) == *other.normalized(opts)
+}
+
+// Equal reports whether both durations are equal after carrying the
+// time part into days and days into weeks with NormalizeOpts, e.g.
+// P1DT24H and P2D or P7D and P1W. Days are assumed to last 24 hours,
+// which does not hold across DST transitions, see EqualAt. Years and
+// months are compared as they are, so P12M and P1Y are not equal.
+// Zero durations are equal regardless of their sign.
+func (d *Duration) Equal(other *Duration) bool {
+	opts := SplitOptions{Weeks: true}
+	return *d.normalized(opts) == *other.normalized(opts)
+}
+
+// EqualAt reports whether both durations are exactly as long when
+// starting at from, as returned by ToDuration. P1M and P30D are equal
+// when starting in April, but not in May.
+func (d *Duration) EqualAt(from time.Time, other *Duration) bool {
+	return d.CompareAt(from, other) == 0
+}
+
+// normalized returns the normalized duration, with nil and negative
+// zero durations replaced by the zero duration
+func (d *Duration) normalized(opts SplitOptions) *Duration {
+	n := d.NormalizeOpts(opts)
+	if n.IsZero() {
+		return &Duration{}
+	}
+	return n
+}
+
+// NormalizeCalendar returns the duration expressed in the largest
+// calendar units when starting at from, e.g. P1M14D for P45D starting
+// on 2024-01-15. The components are taken greedily like in Between, so
+// the result added to from lands on the same instant as d. Unlike with
+// Between, negative durations are taken backwards from from, so -P45D
+// is -P1M16D starting on 2024-03-01: one month back to February 1,
+// then 16 days back to January 16.
+func (d *Duration) NormalizeCalendar(from time.Time) *Duration {
+	if d == nil {
+		return nil
+	}
+	to := d.AddTo(from)
+	if to.Before(from) {
+		return CalendarOptions{}.span(from, to, -1)
+	}
+	return Between(from, to)
+}
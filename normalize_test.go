@@ -0,0 +1,266 @@
+package iso8601duration
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		d        Duration
+		expected Duration
+	}{
+		{"single carry", Duration{Seconds: 75}, Duration{Minutes: 1, Seconds: 15}},
+		{"multi-level carry", Duration{Minutes: 130, Seconds: 75}, Duration{Hours: 2, Minutes: 11, Seconds: 15}},
+		{"carry from nanoseconds", Duration{Minutes: 59, Seconds: 59, Nanoseconds: 1500000000}, Duration{Hours: 1, Seconds: 0, Nanoseconds: 500000000}},
+		{"hours are kept", Duration{Hours: 49}, Duration{Hours: 49}},
+		{"calendar components are kept", Duration{Years: 1, Months: 14, Weeks: 2, Days: 10, Minutes: 60}, Duration{Years: 1, Months: 14, Weeks: 2, Days: 10, Hours: 1}},
+		{"already normalized", Duration{Hours: 1, Minutes: 2, Seconds: 3}, Duration{Hours: 1, Minutes: 2, Seconds: 3}},
+		{"borrow", Duration{Hours: 1, Minutes: -30}, Duration{Minutes: 30}},
+		{"borrow across levels", Duration{Hours: 1, Seconds: -1}, Duration{Minutes: 59, Seconds: 59}},
+		{"borrow from nanoseconds", Duration{Seconds: 1, Nanoseconds: -250000000}, Duration{Nanoseconds: 750000000}},
+		{"negative result", Duration{Hours: -1, Minutes: 30}, Duration{Negative: true, Minutes: 30}},
+		{"negative carry", Duration{Minutes: -130, Seconds: -75}, Duration{Negative: true, Hours: 2, Minutes: 11, Seconds: 15}},
+		{"negative flag", Duration{Negative: true, Minutes: 90}, Duration{Negative: true, Hours: 1, Minutes: 30}},
+		{"negative flag with borrow", Duration{Negative: true, Hours: 1, Minutes: -30}, Duration{Negative: true, Minutes: 30}},
+		{"cancelling out", Duration{Hours: 1, Minutes: -60}, Duration{}},
+		{"mixed with days", Duration{Days: 1, Minutes: -30}, Duration{Days: 1, Minutes: -30}},
+	} {
+		d := tc.d
+		assert.Equal(t, &tc.expected, d.Normalize(), tc.name)
+		assert.Equal(t, tc.d, d, "%s: input modified", tc.name)
+		if !tc.d.IsCalendarOnly() && tc.d.Years == 0 && tc.d.Months == 0 {
+			assert.Equal(t, tc.d.ToEstimatedDuration(), tc.expected.ToEstimatedDuration(), tc.name)
+		}
+	}
+
+	assert.Nil(t, (*Duration)(nil).Normalize())
+}
+
+func TestNormalizeOpts(t *testing.T) {
+	t.Parallel()
+
+	days := SplitOptions{Days: true}
+	weeks := SplitOptions{Weeks: true}
+
+	d := &Duration{Hours: 49, Minutes: 60}
+	assert.Equal(t, &Duration{Days: 2, Hours: 2}, d.NormalizeOpts(days))
+	assert.Equal(t, &Duration{Days: 2, Hours: 2}, d.NormalizeOpts(weeks))
+
+	d = &Duration{Days: 13, Hours: 24}
+	assert.Equal(t, &Duration{Days: 14}, d.NormalizeOpts(days))
+	assert.Equal(t, &Duration{Weeks: 2}, d.NormalizeOpts(weeks))
+
+	// borrowing from days
+	d = &Duration{Days: 1, Minutes: -30}
+	assert.Equal(t, &Duration{Hours: 23, Minutes: 30}, d.NormalizeOpts(days))
+
+	// borrowing from weeks
+	d = &Duration{Weeks: 1, Seconds: -1}
+	assert.Equal(t, &Duration{Days: 6, Hours: 23, Minutes: 59, Seconds: 59}, d.NormalizeOpts(weeks))
+
+	d = &Duration{Negative: true, Days: 1, Hours: 25}
+	assert.Equal(t, &Duration{Negative: true, Days: 2, Hours: 1}, d.NormalizeOpts(days))
+
+	// years and months are never touched
+	d = &Duration{Years: 1, Months: 13, Days: 40}
+	assert.Equal(t, &Duration{Years: 1, Months: 13, Weeks: 5, Days: 5}, d.NormalizeOpts(weeks))
+}
+
+func TestEqualNormalized(t *testing.T) {
+	t.Parallel()
+
+	for _, pair := range [][2]string{
+		{"PT60S", "PT1M"},
+		{"PT90M", "PT1H30M"},
+		{"PT3600S", "PT1H"},
+		{"PT1.5S", "PT1,5S"},
+		{"P1DT25H", "P1DT24H60M"},
+		{"-PT120S", "-PT2M"},
+		{"PT0S", "-PT0S"},
+		{"P1Y2MT60S", "P1Y2MT1M"},
+	} {
+		a, err := FromString(pair[0])
+		assert.NoError(t, err)
+		b, err := FromString(pair[1])
+		assert.NoError(t, err)
+		assert.True(t, a.EqualNormalized(b), "%s = %s", pair[0], pair[1])
+		assert.True(t, b.EqualNormalized(a), "%s = %s", pair[1], pair[0])
+	}
+
+	for _, pair := range [][2]string{
+		{"PT24H", "P1D"},
+		{"P7D", "P1W"},
+		{"P12M", "P1Y"},
+		{"PT1M", "-PT1M"},
+		{"PT61S", "PT1M"},
+	} {
+		a, err := FromString(pair[0])
+		assert.NoError(t, err)
+		b, err := FromString(pair[1])
+		assert.NoError(t, err)
+		assert.False(t, a.EqualNormalized(b), "%s != %s", pair[0], pair[1])
+	}
+
+	// unlike a raw comparison, the sign representation does not matter
+	a := &Duration{Minutes: -1}
+	b := &Duration{Negative: true, Seconds: 60}
+	assert.NotEqual(t, a, b)
+	assert.True(t, a.EqualNormalized(b))
+	assert.True(t, (&Duration{Hours: 1, Minutes: -60}).EqualNormalized(nil))
+
+	assert.True(t, (*Duration)(nil).EqualNormalized(&Duration{}))
+	assert.False(t, (*Duration)(nil).EqualNormalized(&Duration{Seconds: 1}))
+}
+
+func TestNormalizeCalendar(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d        string
+		from     time.Time
+		expected string
+	}{
+		{"P45D", date(2024, 1, 15, 0, 0, 0), "P1M14D"},
+		{"P45D", date(2023, 1, 15, 0, 0, 0), "P1M14D"},
+		{"P30D", date(2024, 2, 1, 0, 0, 0), "P1M1D"},
+		{"P29D", date(2024, 2, 1, 0, 0, 0), "P1M"},
+		{"P366D", date(2024, 1, 1, 0, 0, 0), "P1Y"},
+		{"P365D", date(2024, 1, 1, 0, 0, 0), "P11M30D"},
+		// like AddTo, Between rolls February 29 over into March 1
+		{"P1Y", date(2024, 2, 29, 0, 0, 0), "P1Y"},
+		{"P365D", date(2024, 2, 29, 0, 0, 0), "P11M30D"},
+		{"P366D", date(2024, 2, 29, 0, 0, 0), "P1Y"},
+		{"P4W", date(2023, 2, 1, 0, 0, 0), "P1M"},
+		{"PT49H", date(2024, 1, 31, 0, 0, 0), "P2DT1H"},
+		{"P13M", date(2024, 1, 1, 0, 0, 0), "P1Y1M"},
+		{"P1M", date(2024, 1, 31, 0, 0, 0), "P1M"},
+		{"P31D", date(2024, 1, 31, 0, 0, 0), "P1M"},
+		{"PT0S", date(2024, 1, 1, 0, 0, 0), "PT0S"},
+	} {
+		d, err := FromString(tc.d)
+		assert.NoError(t, err)
+
+		n := d.NormalizeCalendar(tc.from)
+		assert.Equal(t, tc.expected, n.String(), "%s from %s", tc.d, tc.from)
+		assert.True(t, d.AddTo(tc.from).Equal(n.AddTo(tc.from)), "%s from %s", tc.d, tc.from)
+	}
+
+	// negative durations are taken backwards from the anchor
+	for _, tc := range []struct {
+		d        string
+		from     time.Time
+		expected string
+	}{
+		{"-P45D", date(2024, 3, 1, 0, 0, 0), "-P1M16D"},
+		{"-P45D", date(2023, 3, 1, 0, 0, 0), "-P1M17D"},
+		{"-P29D", date(2024, 3, 1, 0, 0, 0), "-P1M"},
+		{"-P28D", date(2024, 3, 1, 0, 0, 0), "-P28D"},
+		{"-P28D", date(2023, 3, 1, 0, 0, 0), "-P1M"},
+		{"-P365D", date(2025, 2, 28, 0, 0, 0), "-P11M28D"},
+		{"-P366D", date(2025, 2, 28, 0, 0, 0), "-P1Y"},
+		// like AddTo, February 29 rolls over into March 1
+		{"-P1Y", date(2024, 2, 29, 0, 0, 0), "-P11M28D"},
+		{"-P31D", date(2024, 3, 31, 0, 0, 0), "-P1M2D"},
+		{"-P3M25DT22H", time.Date(2020, 8, 17, 14, 0, 0, 0, time.UTC), "-P3M25DT22H"},
+		{"-PT49H", date(2024, 3, 1, 0, 0, 0), "-P2DT1H"},
+	} {
+		d := MustFromString(tc.d)
+		n := d.NormalizeCalendar(tc.from)
+		assert.Equal(t, tc.expected, n.String(), "%s from %s", tc.d, tc.from)
+		assert.True(t, n.AddTo(tc.from).Equal(d.AddTo(tc.from)), "%s from %s", tc.d, tc.from)
+	}
+
+	// the result lands on the same instant for random durations
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		d := &Duration{
+			Negative: rnd.Intn(2) == 0,
+			Years:    rnd.Intn(3),
+			Months:   rnd.Intn(24),
+			Days:     rnd.Intn(100),
+			Hours:    rnd.Intn(48),
+			Minutes:  rnd.Intn(120),
+		}
+		from := time.Date(2015+rnd.Intn(10), time.Month(1+rnd.Intn(12)), 1+rnd.Intn(31), rnd.Intn(24), 0, 0, 0, time.UTC)
+		n := d.NormalizeCalendar(from)
+		assert.True(t, n.AddTo(from).Equal(d.AddTo(from)), "%s from %s: %s", d, from, n)
+		assert.False(t, n.hasMixedSigns())
+	}
+
+	assert.Nil(t, (*Duration)(nil).NormalizeCalendar(date(2024, 1, 1, 0, 0, 0)))
+}
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	for _, pair := range [][2]string{
+		{"P1DT24H", "P2D"},
+		{"PT60S", "PT1M"},
+		{"PT1440M", "P1D"},
+		{"P7D", "P1W"},
+		{"P6DT24H", "P1W"},
+		{"P1W7D", "P2W"},
+		{"P13DT24H", "P2W"},
+		{"P1Y2MT86400S", "P1Y2M1D"},
+		{"-PT24H", "-P1D"},
+		{"PT0S", "-P0D"},
+	} {
+		a := MustFromString(pair[0])
+		b := MustFromString(pair[1])
+		assert.True(t, a.Equal(b), "%s = %s", pair[0], pair[1])
+		assert.True(t, b.Equal(a), "%s = %s", pair[1], pair[0])
+	}
+
+	for _, pair := range [][2]string{
+		{"P6DT23H", "P1W"},
+		{"P8D", "P1W"},
+		{"P1M", "P30D"},
+		{"P12M", "P1Y"},
+		{"P1D", "-P1D"},
+		{"PT24H0.000000001S", "P1D"},
+	} {
+		a := MustFromString(pair[0])
+		b := MustFromString(pair[1])
+		assert.False(t, a.Equal(b), "%s != %s", pair[0], pair[1])
+		assert.False(t, b.Equal(a), "%s != %s", pair[1], pair[0])
+	}
+
+	// unlike EqualNormalized, hours are carried into days
+	a, b := &Duration{Hours: 24}, &Duration{Days: 1}
+	assert.True(t, a.Equal(b))
+	assert.False(t, a.EqualNormalized(b))
+
+	assert.True(t, (&Duration{Days: 1, Hours: -24}).Equal(nil))
+	assert.True(t, (*Duration)(nil).Equal(nil))
+}
+
+func TestEqualAt(t *testing.T) {
+	t.Parallel()
+
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	month, days := &Duration{Months: 1}, &Duration{Days: 30}
+	assert.True(t, month.EqualAt(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC), days))
+	assert.False(t, month.EqualAt(time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), days))
+	assert.False(t, month.Equal(days))
+
+	// weeks and days are always equal
+	week, seven := &Duration{Weeks: 1}, &Duration{Days: 7}
+	assert.True(t, week.EqualAt(time.Date(2021, 3, 25, 0, 0, 0, 0, berlin), seven))
+
+	// while days and hours are not across DST transitions
+	day, hours := &Duration{Days: 1}, &Duration{Hours: 24}
+	assert.True(t, day.Equal(hours))
+	assert.True(t, day.EqualAt(time.Date(2021, 3, 27, 12, 0, 0, 0, time.UTC), hours))
+	assert.False(t, day.EqualAt(time.Date(2021, 3, 27, 12, 0, 0, 0, berlin), hours))
+	assert.True(t, day.EqualAt(time.Date(2021, 3, 27, 12, 0, 0, 0, berlin), &Duration{Hours: 23}))
+
+	assert.True(t, (*Duration)(nil).EqualAt(time.Now(), &Duration{}))
+}
@@ -0,0 +1,64 @@
+package iso8601duration
+
+import "time"
+
+// Occurrences iterates over the times Start, Start plus Every, Start
+// plus twice Every and so on. Each occurrence is computed from Start by
+// multiplying Every rather than by adding it up, so days which do not
+// exist in a month do not drift: monthly occurrences starting on
+// January 31 fall on the last day of February and on March 31. Unlike
+// with AddTo, such days are clamped to the end of the month instead of
+// rolling over into the next one, see ClampToMonthEnd.
+//
+// The zero value of Limit and Until means no bound, so the iteration
+// only ends when a multiple of Every overflows.
+type Occurrences struct {
+	// Start is the first occurrence
+	Start time.Time
+	// Every is the duration between occurrences
+	Every *Duration
+	// Limit ends the iteration after that many occurrences if positive
+	Limit int
+	// Until ends the iteration at the first occurrence after Until, or
+	// before Until if Every is negative, unless Until is zero
+	Until time.Time
+
+	n int
+}
+
+// Occurrences returns an iterator over the occurrences of the duration
+// starting at start, see Occurrences. Set Limit or Until to bound it.
+func (d *Duration) Occurrences(start time.Time) *Occurrences {
+	return &Occurrences{Start: start, Every: d}
+}
+
+// Next returns the next occurrence and true, or the zero time and false
+// when the iteration has ended
+func (o *Occurrences) Next() (time.Time, bool) {
+	if o.Limit > 0 && o.n >= o.Limit {
+		return time.Time{}, false
+	}
+	step, err := o.Every.MulChecked(int64(o.n))
+	if err != nil {
+		return time.Time{}, false
+	}
+	t := step.addToClamped(o.Start)
+	if !o.Until.IsZero() {
+		if o.Every.ToEstimatedDuration() < 0 {
+			if t.Before(o.Until) {
+				return time.Time{}, false
+			}
+		} else if t.After(o.Until) {
+			return time.Time{}, false
+		}
+	}
+	o.n++
+	return t, true
+}
+
+// addToClamped works like AddTo, but clamps the day of the month to the
+// last day of the month after applying years and months
+func (d *Duration) addToClamped(t time.Time) time.Time {
+	t, _ = CalendarOptions{MonthEnd: ClampToMonthEnd}.AddTo(d, t)
+	return t
+}
@@ -0,0 +1,126 @@
+package iso8601duration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collect returns up to max occurrences
+func collect(o *Occurrences, max int) []time.Time {
+	var res []time.Time
+	for len(res) < max {
+		t, ok := o.Next()
+		if !ok {
+			break
+		}
+		res = append(res, t)
+	}
+	return res
+}
+
+func TestOccurrencesEndOfMonth(t *testing.T) {
+	t.Parallel()
+
+	o := MustFromString("P1M").Occurrences(date(2024, time.January, 31, 9, 30, 0))
+	assert.Equal(t, []time.Time{
+		date(2024, time.January, 31, 9, 30, 0),
+		date(2024, time.February, 29, 9, 30, 0),
+		date(2024, time.March, 31, 9, 30, 0),
+		date(2024, time.April, 30, 9, 30, 0),
+		date(2024, time.May, 31, 9, 30, 0),
+	}, collect(o, 5))
+
+	o = MustFromString("P1M").Occurrences(date(2023, time.January, 31, 9, 30, 0))
+	assert.Equal(t, []time.Time{
+		date(2023, time.January, 31, 9, 30, 0),
+		date(2023, time.February, 28, 9, 30, 0),
+		date(2023, time.March, 31, 9, 30, 0),
+	}, collect(o, 3))
+
+	// leap days come back every four years
+	o = MustFromString("P1Y").Occurrences(date(2024, time.February, 29, 9, 30, 0))
+	assert.Equal(t, []time.Time{
+		date(2024, time.February, 29, 9, 30, 0),
+		date(2025, time.February, 28, 9, 30, 0),
+		date(2026, time.February, 28, 9, 30, 0),
+		date(2027, time.February, 28, 9, 30, 0),
+		date(2028, time.February, 29, 9, 30, 0),
+	}, collect(o, 5))
+
+	// the remaining components are added after clamping
+	o = MustFromString("P1M1D").Occurrences(date(2023, time.January, 31, 9, 30, 0))
+	assert.Equal(t, []time.Time{
+		date(2023, time.January, 31, 9, 30, 0),
+		date(2023, time.March, 1, 9, 30, 0),
+		date(2023, time.April, 2, 9, 30, 0),
+	}, collect(o, 3))
+
+	// negative durations count backwards
+	o = MustFromString("-P1M").Occurrences(date(2023, time.March, 31, 9, 30, 0))
+	assert.Equal(t, []time.Time{
+		date(2023, time.March, 31, 9, 30, 0),
+		date(2023, time.February, 28, 9, 30, 0),
+		date(2023, time.January, 31, 9, 30, 0),
+		date(2022, time.December, 31, 9, 30, 0),
+	}, collect(o, 4))
+}
+
+func TestOccurrencesLimit(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	o := MustFromString("PT1H30M").Occurrences(start)
+	o.Limit = 3
+	assert.Equal(t, []time.Time{
+		start,
+		start.Add(90 * time.Minute),
+		start.Add(180 * time.Minute),
+	}, collect(o, 10))
+
+	_, ok := o.Next()
+	assert.False(t, ok)
+}
+
+func TestOccurrencesUntil(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	o := MustFromString("P1W").Occurrences(start)
+	o.Until = time.Date(2023, time.January, 22, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []time.Time{
+		start,
+		start.AddDate(0, 0, 7),
+		start.AddDate(0, 0, 14),
+		start.AddDate(0, 0, 21),
+	}, collect(o, 10))
+
+	// the first bound reached ends the iteration
+	o = MustFromString("P1W").Occurrences(start)
+	o.Until = time.Date(2023, time.January, 22, 0, 0, 0, 0, time.UTC)
+	o.Limit = 2
+	assert.Len(t, collect(o, 10), 2)
+
+	// negative durations end before Until
+	o = MustFromString("-P1D").Occurrences(start)
+	o.Until = start.AddDate(0, 0, -2)
+	assert.Equal(t, []time.Time{
+		start,
+		start.AddDate(0, 0, -1),
+		start.AddDate(0, 0, -2),
+	}, collect(o, 10))
+
+	// Until before Start yields nothing
+	o = MustFromString("P1D").Occurrences(start)
+	o.Until = start.Add(-time.Nanosecond)
+	assert.Empty(t, collect(o, 10))
+}
+
+func TestOccurrencesOverflow(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	o := (&Duration{Seconds: 1 << 62}).Occurrences(start)
+	assert.Len(t, collect(o, 10), 2)
+}
@@ -0,0 +1,35 @@
+package iso8601duration
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FromRepeatingString parses the duration of a repeating interval,
+// e.g. R5/P1D. A missing number of repetitions as in R/P1W means an
+// unbounded interval, for which count is -1.
+func FromRepeatingString(s string) (count int, d *Duration, err error) {
+	slash := strings.IndexByte(s, '/')
+	if len(s) == 0 || s[0] != 'R' || slash < 0 {
+		return 0, nil, ErrBadFormat
+	}
+
+	count = -1
+	if n := s[1:slash]; n != "" {
+		for _, c := range n {
+			if c < '0' || c > '9' {
+				return 0, nil, ErrBadFormat
+			}
+		}
+		count, err = strconv.Atoi(n)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	d, err = FromString(s[slash+1:])
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, d, nil
+}
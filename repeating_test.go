@@ -0,0 +1,36 @@
+package iso8601duration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromRepeatingString(t *testing.T) {
+	t.Parallel()
+
+	count, d, err := FromRepeatingString("R5/P1D")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, count)
+	assert.Equal(t, &Duration{Days: 1}, d)
+
+	// unbounded
+	count, d, err = FromRepeatingString("R/P1W")
+	assert.Nil(t, err)
+	assert.Equal(t, -1, count)
+	assert.Equal(t, &Duration{Weeks: 1}, d)
+
+	count, d, err = FromRepeatingString("R0/PT1H30M")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, &Duration{Hours: 1, Minutes: 30}, d)
+
+	for _, s := range []string{"R5P1D", "5/P1D", "", "R", "R-1/P1D", "R+1/P1D", "Rx/P1D", "R5/asdf"} {
+		_, d, err = FromRepeatingString(s)
+		assert.Nil(t, d)
+		assert.Equal(t, ErrBadFormat, err, s)
+	}
+
+	_, _, err = FromRepeatingString("R5/")
+	assert.Equal(t, ErrEmpty, err)
+}
@@ -0,0 +1,159 @@
+package iso8601duration
+
+import (
+	"math"
+	"math/big"
+)
+
+// Unit names a component of a duration, ordered from years down to
+// seconds
+type Unit int
+
+const (
+	Years Unit = iota
+	Months
+	Weeks
+	Days
+	Hours
+	Minutes
+	Seconds
+)
+
+// Round returns the duration rounded to the nearest multiple of
+// multiple units, with halfway values rounded away from zero, e.g.
+// PT1H45M for PT1H47M rounded to 15 minutes or P6W for P40D rounded to
+// weeks. See RoundWith for other rounding modes.
+//
+// The components smaller than unit are folded into it, using exact
+// factors where possible and the lengths of ToEstimatedDuration for
+// days and time parts of years and months. For weeks and smaller
+// units, all components from weeks down are taken into account, so
+// PT1H53M rounded to 15 minutes is PT2H like with time.Duration.Round,
+// while years and months are left as they are. The result is spread
+// over the components from the largest one present down to unit, so
+// PT30M rounded to days is zero and PT12H rounded to days is P1D.
+//
+// Round returns the duration unchanged if multiple is not positive or
+// unit is unknown. It panics with ErrOutOfRange if the result
+// overflows.
+func (d *Duration) Round(unit Unit, multiple int64) *Duration {
+	return d.RoundWith(unit, multiple, RoundHalfUp)
+}
+
+// RoundWith works like Round, but rounds according to mode, e.g.
+// PT1H rounded down to the hour with RoundFloor for PT1H59M.
+func (d *Duration) RoundWith(unit Unit, multiple int64, mode RoundingMode) *Duration {
+	if multiple <= 0 || unit < Years || unit > Seconds {
+		var res Duration
+		if d != nil {
+			res = *d
+		}
+		return &res
+	}
+	s := d.signed()
+
+	comps := s.components()
+	lengths := Estimator{}.lengths()
+
+	// the first component folded into unit, the ones before are kept
+	first := int(Weeks)
+	if unit < Weeks {
+		first = int(Years)
+	}
+
+	// x is the folded duration in units, with the exact part counted
+	// in whole units and the rest in nanoseconds
+	x := new(big.Rat)
+	rest := big.NewInt(int64(s.Nanoseconds))
+	for i := first; i < len(comps); i++ {
+		v := big.NewInt(int64(comps[i]))
+		switch {
+		case i <= int(unit):
+			x.Add(x, new(big.Rat).SetInt(v.Mul(v, big.NewInt(int64(unitFactor(int(unit), i))))))
+		case i == int(Months):
+			x.Add(x, new(big.Rat).SetFrac(v, big.NewInt(12)))
+		default:
+			rest.Add(rest, v.Mul(v, big.NewInt(int64(lengths[i]))))
+		}
+	}
+	x.Add(x, new(big.Rat).SetFrac(rest, big.NewInt(int64(lengths[unit]))))
+
+	neg := x.Sign() < 0
+	x.Abs(x)
+	x.Quo(x, new(big.Rat).SetInt64(multiple))
+	whole := new(big.Int).Quo(x.Num(), x.Denom())
+	frac := new(big.Rat).Sub(x, new(big.Rat).SetInt(whole))
+	if roundUp(whole, frac, mode, neg) {
+		whole.Add(whole, big.NewInt(1))
+	}
+	whole.Mul(whole, big.NewInt(multiple))
+	if !whole.IsInt64() || whole.Int64() > math.MaxInt {
+		panic(ErrOutOfRange)
+	}
+	v := int(whole.Int64())
+
+	// spread v from the largest folded component present down to unit
+	top := int(unit)
+	for i := first; i < int(unit); i++ {
+		if comps[i] != 0 {
+			top = i
+			break
+		}
+	}
+	for i := first; i < len(comps); i++ {
+		comps[i] = 0
+	}
+	for i := int(unit); i > top; i-- {
+		f := unitFactor(i, i-1)
+		comps[i] = v % f
+		v /= f
+	}
+	comps[top] = v
+	if neg {
+		for i := top; i <= int(unit); i++ {
+			comps[i] = -comps[i]
+		}
+	}
+
+	res, err := fromSigned(comps, 0)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// Truncate returns the duration with all components smaller than unit
+// set to zero, e.g. P1Y2M3D for P1Y2M3DT4H5M6S truncated to days.
+// Nothing is rounded or carried, so P1W3D truncated to weeks is P1W.
+// As the sign is kept, negative durations are truncated towards zero.
+// Truncate returns the duration unchanged if unit is unknown.
+func (d *Duration) Truncate(unit Unit) *Duration {
+	var res Duration
+	if d != nil {
+		res = *d
+	}
+	if unit < Years || unit > Seconds {
+		return &res
+	}
+	comps := res.components()
+	for i := int(unit) + 1; i < len(comps); i++ {
+		comps[i] = 0
+	}
+	res.setComponents(comps)
+	res.Nanoseconds = 0
+	if res.IsZero() {
+		res.Negative = false
+	}
+	return &res
+}
+
+// unitFactor returns how many of component i make up one of the
+// larger component j, the product of carries in between. It is one if
+// i equals j and zero if the units do not convert exactly.
+func unitFactor(i, j int) int {
+	f := 1
+	for k := j; k < i; k++ {
+		f *= carries[k]
+	}
+	return f
+}
@@ -0,0 +1,183 @@
+package iso8601duration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRound(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		input    string
+		unit     Unit
+		multiple int64
+		expected string
+	}{
+		{"PT1H47M", Minutes, 15, "PT1H45M"},
+		{"PT1H53M", Minutes, 15, "PT2H"},
+		{"PT1H52M30S", Minutes, 1, "PT1H53M"},
+		{"PT1H52M29.9S", Minutes, 1, "PT1H52M"},
+		{"P40D", Weeks, 1, "P6W"},
+		{"P38D", Weeks, 1, "P5W"},
+		{"P1W3DT12H", Weeks, 1, "P2W"},
+		{"P1W3DT11H", Weeks, 1, "P1W"},
+		{"PT30M", Days, 1, "PT0S"},
+		{"PT12H", Days, 1, "P1D"},
+		{"PT36H", Days, 1, "P2D"},
+		{"PT1.5S", Seconds, 1, "PT2S"},
+		{"PT2.5S", Seconds, 1, "PT3S"},
+		{"-PT2.5S", Seconds, 1, "-PT3S"},
+		{"-PT1H47M", Minutes, 15, "-PT1H45M"},
+		{"PT100M", Hours, 1, "PT2H"},
+		{"P1DT2H", Hours, 6, "P1D"},
+		{"P1DT3H", Hours, 6, "P1DT6H"},
+		// years and months are kept when rounding to fixed units
+		{"P1Y2M3DT14H", Days, 1, "P1Y2M4D"},
+		// calendar units use the estimated lengths
+		{"P1Y5M", Years, 1, "P1Y"},
+		{"P1Y6M", Years, 1, "P2Y"},
+		{"P1M20D", Months, 1, "P2M"},
+		{"P1M10D", Months, 1, "P1M"},
+		{"P1Y11M20D", Months, 1, "P2Y"},
+		{"P1Y11M20D", Months, 3, "P2Y"},
+		{"P1Y5M", Months, 6, "P1Y6M"},
+		{"P200D", Years, 1, "P1Y"},
+		{"P100D", Years, 1, "PT0S"},
+		{"P2W", Months, 1, "PT0S"},
+	} {
+		d := MustFromString(tc.input)
+		assert.Equal(t, tc.expected, d.Round(tc.unit, tc.multiple).String(), "%s to %d of %d", tc.input, tc.multiple, tc.unit)
+	}
+
+	// the input is not modified
+	d := &Duration{Hours: 1, Minutes: 47}
+	d.Round(Minutes, 15)
+	assert.Equal(t, &Duration{Hours: 1, Minutes: 47}, d)
+
+	// non-positive multiples and unknown units leave the duration as is
+	assert.Equal(t, d, d.Round(Minutes, 0))
+	assert.Equal(t, d, d.Round(Minutes, -15))
+	assert.Equal(t, d, d.Round(Unit(42), 1))
+	assert.Equal(t, &Duration{}, (*Duration)(nil).Round(Hours, 1))
+	d = &Duration{Negative: true, Days: 1}
+	assert.Equal(t, d, d.Round(Days, 0))
+}
+
+func TestRoundLikeTimeDuration(t *testing.T) {
+	t.Parallel()
+
+	units := map[Unit]time.Duration{
+		Weeks:   7 * 24 * time.Hour,
+		Days:    24 * time.Hour,
+		Hours:   time.Hour,
+		Minutes: time.Minute,
+		Seconds: time.Second,
+	}
+	for _, td := range []time.Duration{
+		0,
+		time.Second,
+		1500 * time.Millisecond,
+		-1500 * time.Millisecond,
+		time.Hour + 52*time.Minute,
+		time.Hour + 7*time.Minute,
+		-(time.Hour + 7*time.Minute),
+		29*time.Hour + 59*time.Minute + 59*time.Second,
+		100*time.Hour + 30*time.Minute,
+		-(100*time.Hour + 30*time.Minute),
+	} {
+		for unit, length := range units {
+			for _, multiple := range []int64{1, 2, 7, 15} {
+				d := FromTimeDurationOpts(td, SplitOptions{Weeks: true})
+				expected := td.Round(time.Duration(multiple) * length)
+				assert.Equal(t, expected, d.Round(unit, multiple).ToEstimatedDuration(),
+					"%s to %d of %d", td, multiple, unit)
+			}
+		}
+	}
+}
+
+func TestRoundWith(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		input    string
+		mode     RoundingMode
+		expected string
+	}{
+		{"PT1H59M", RoundFloor, "PT1H"},
+		{"PT1H1M", RoundCeil, "PT2H"},
+		{"PT1H", RoundCeil, "PT1H"},
+		{"-PT1H1M", RoundFloor, "-PT2H"},
+		{"-PT1H59M", RoundCeil, "-PT1H"},
+		{"PT1H30M", RoundHalfEven, "PT2H"},
+		{"PT2H30M", RoundHalfEven, "PT2H"},
+		{"PT2H30M", RoundHalfUp, "PT3H"},
+		{"-PT2H30M", RoundHalfUp, "-PT3H"},
+	} {
+		d := MustFromString(tc.input)
+		assert.Equal(t, tc.expected, d.RoundWith(Hours, 1, tc.mode).String(), "%s %d", tc.input, tc.mode)
+	}
+}
+
+func TestRoundMixedSigns(t *testing.T) {
+	t.Parallel()
+
+	d := &Duration{Hours: 2, Minutes: -10}
+	assert.Equal(t, &Duration{Hours: 2}, d.Round(Hours, 1))
+
+	d = &Duration{Days: -1, Hours: 2}
+	assert.Equal(t, &Duration{Negative: true, Hours: 22}, d.Round(Hours, 1))
+
+	// kept months may disagree with the rounded part
+	d = &Duration{Months: 1, Days: -40}
+	assert.Equal(t, &Duration{Months: 1, Weeks: -6}, d.Round(Weeks, 1))
+}
+
+func TestRoundOverflow(t *testing.T) {
+	t.Parallel()
+
+	d := &Duration{Hours: 1 << 62}
+	assert.PanicsWithValue(t, ErrOutOfRange, func() { d.Round(Seconds, 1) })
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		input    string
+		unit     Unit
+		expected string
+	}{
+		{"P1Y2M3DT4H5M6S", Days, "P1Y2M3D"},
+		{"P1Y2M3DT4H5M6S", Years, "P1Y"},
+		{"P1Y2M3DT4H5M6S", Months, "P1Y2M"},
+		{"P1Y2M3DT4H5M6S", Hours, "P1Y2M3DT4H"},
+		{"P1Y2M3DT4H5M6S", Minutes, "P1Y2M3DT4H5M"},
+		{"P1Y2M3DT4H5M6.7S", Seconds, "P1Y2M3DT4H5M6S"},
+		{"P1W3D", Weeks, "P1W"},
+		{"P10D", Weeks, "PT0S"},
+		{"PT59M", Hours, "PT0S"},
+		{"PT90M", Hours, "PT0S"},
+		{"-P1DT23H", Days, "-P1D"},
+		{"-PT1.9S", Seconds, "-PT1S"},
+		{"-PT0.5S", Seconds, "PT0S"},
+	} {
+		d := MustFromString(tc.input)
+		assert.Equal(t, tc.expected, d.Truncate(tc.unit).String(), "%s to %d", tc.input, tc.unit)
+	}
+
+	// the input is not modified
+	d := &Duration{Days: 1, Hours: 2}
+	assert.Equal(t, &Duration{Days: 1}, d.Truncate(Days))
+	assert.Equal(t, &Duration{Days: 1, Hours: 2}, d)
+
+	// components with their own sign keep it
+	d = &Duration{Days: -1, Hours: -2}
+	assert.Equal(t, &Duration{Days: -1}, d.Truncate(Days))
+
+	assert.Equal(t, d, d.Truncate(Unit(-1)))
+	assert.Equal(t, &Duration{}, (*Duration)(nil).Truncate(Days))
+}
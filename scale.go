@@ -0,0 +1,129 @@
+package iso8601duration
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"time"
+)
+
+// ErrNotExact is returned by ScaleFloat when a fraction of a month
+// would have to be converted into smaller units
+var ErrNotExact = errors.New("fraction of a month has no exact length")
+
+// RoundingMode controls how ScaleFloat rounds fractions of a nanosecond
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest nanosecond, with halfway values
+	// rounded away from zero
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest nanosecond, with halfway
+	// values rounded to the even nanosecond
+	RoundHalfEven
+	// RoundFloor rounds towards negative infinity
+	RoundFloor
+	// RoundCeil rounds towards positive infinity
+	RoundCeil
+)
+
+// ScaleFloat returns the duration multiplied by f, e.g. P1DT12H for
+// P1D times 1.5. Fractions of a component are spilled into the next
+// smaller unit where that is exact: years into months, weeks into days,
+// days into hours, assuming days of 24 hours, and so on down to
+// nanoseconds, which are rounded according to mode. Like with Mul, the
+// result is not normalized.
+//
+// ScaleFloat returns ErrNotExact if a fraction of a month remains, see
+// ScaleFloatWith, ErrOutOfRange if f is NaN or infinite or a component
+// overflows, and ErrMixedSigns if the components of d have different
+// signs.
+func (d *Duration) ScaleFloat(f float64, mode RoundingMode) (*Duration, error) {
+	return d.scaleFloat(f, mode, nil)
+}
+
+// ScaleFloatWith works like ScaleFloat, but spills fractions of a
+// month into days using the month length of e. For example, P1M times
+// 1.5 is P1M15D with the zero Estimator.
+func (d *Duration) ScaleFloatWith(f float64, mode RoundingMode, e Estimator) (*Duration, error) {
+	return d.scaleFloat(f, mode, &e)
+}
+
+func (d *Duration) scaleFloat(f float64, mode RoundingMode, e *Estimator) (*Duration, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, ErrOutOfRange
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	c := d.canonical()
+	if c == nil {
+		c = &Duration{}
+	}
+	neg := c.Negative != (f < 0)
+	factor := new(big.Rat).SetFloat64(math.Abs(f))
+
+	comps := c.components()
+	vals := [8]int{comps[0], comps[1], comps[2], comps[3], comps[4], comps[5], comps[6], c.Nanoseconds}
+
+	var (
+		out   [8]int
+		spill [8]big.Rat
+	)
+	for i, v := range vals {
+		x := new(big.Rat).SetInt64(int64(v))
+		x.Mul(x, factor)
+		x.Add(x, &spill[i])
+
+		whole := new(big.Int).Quo(x.Num(), x.Denom())
+		frac := new(big.Rat).Sub(x, new(big.Rat).SetInt(whole))
+		if i == 7 && roundUp(whole, frac, mode, neg) {
+			whole.Add(whole, big.NewInt(1))
+		}
+		if !whole.IsInt64() || whole.Int64() > math.MaxInt {
+			return nil, ErrOutOfRange
+		}
+		out[i] = int(whole.Int64())
+
+		switch {
+		case i == 7 || frac.Sign() == 0:
+		case i == 1:
+			// months spill into days, skipping weeks
+			if e == nil {
+				return nil, ErrNotExact
+			}
+			days := new(big.Rat).SetFrac64(int64(e.lengths()[1]), int64(24*time.Hour))
+			spill[3].Mul(frac, days)
+		default:
+			spill[i+1].Mul(frac, new(big.Rat).SetInt64(int64(carries[i])))
+		}
+	}
+
+	var ok bool
+	if out[6], ok = addInt(out[6], out[7]/1e9); !ok {
+		return nil, ErrOutOfRange
+	}
+	res := &Duration{Nanoseconds: out[7] % 1e9}
+	res.setComponents([7]int{out[0], out[1], out[2], out[3], out[4], out[5], out[6]})
+	res.Negative = neg && !res.IsZero()
+	return res, nil
+}
+
+// roundUp reports whether the magnitude whole+frac is to be rounded up
+// rather than truncated, given that the result is negative if neg is
+// set
+func roundUp(whole *big.Int, frac *big.Rat, mode RoundingMode, neg bool) bool {
+	if frac.Sign() == 0 {
+		return false
+	}
+	switch mode {
+	case RoundHalfEven:
+		cmp := frac.Cmp(big.NewRat(1, 2))
+		return cmp > 0 || (cmp == 0 && whole.Bit(0) == 1)
+	case RoundFloor:
+		return neg
+	case RoundCeil:
+		return !neg
+	}
+	return frac.Cmp(big.NewRat(1, 2)) >= 0
+}
@@ -0,0 +1,113 @@
+package iso8601duration
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaleFloat(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		d        string
+		f        float64
+		expected string
+	}{
+		{"P1D", 1.5, "P1DT12H"},
+		{"P30D", 1.5, "P45D"},
+		{"P1Y", 0.5, "P6M"},
+		{"P1Y", 1.25, "P1Y3M"},
+		{"P1W", 0.5, "P3DT12H"},
+		{"PT1H", 0.75, "PT45M"},
+		{"PT1S", 0.1, "PT0.1S"},
+		{"PT0.5S", 10, "PT5S"},
+		{"PT1H30M", 2, "PT2H60M"},
+		{"P1M", 2, "P2M"},
+		{"P1M", 0, "PT0S"},
+		{"P1D", -1.5, "-P1DT12H"},
+		{"-P1D", 0.5, "-PT12H"},
+		{"-P1D", -0.5, "PT12H"},
+	} {
+		d, err := FromString(tc.d)
+		assert.NoError(t, err)
+
+		s, err := d.ScaleFloat(tc.f, RoundHalfEven)
+		assert.NoError(t, err, "%s * %g", tc.d, tc.f)
+		assert.Equal(t, tc.expected, s.String(), "%s * %g", tc.d, tc.f)
+	}
+
+	// fractions of a month have no exact length
+	_, err := (&Duration{Months: 1}).ScaleFloat(1.5, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrNotExact)
+	_, err = (&Duration{Years: 1}).ScaleFloat(0.125, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrNotExact)
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err = (&Duration{Days: 1}).ScaleFloat(f, RoundHalfUp)
+		assert.ErrorIs(t, err, ErrOutOfRange)
+	}
+	_, err = (&Duration{Days: math.MaxInt / 2}).ScaleFloat(3, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	_, err = (&Duration{Days: 1, Hours: -1}).ScaleFloat(2, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrMixedSigns)
+
+	s, err := (*Duration)(nil).ScaleFloat(2, RoundHalfUp)
+	assert.NoError(t, err)
+	assert.Equal(t, &Duration{}, s)
+}
+
+func TestScaleFloatWith(t *testing.T) {
+	t.Parallel()
+
+	month := &Duration{Months: 1}
+
+	s, err := month.ScaleFloatWith(1.5, RoundHalfUp, Estimator{})
+	assert.NoError(t, err)
+	assert.Equal(t, "P1M15D", s.String())
+
+	s, err = month.ScaleFloatWith(0.5, RoundHalfUp, Estimator{Month: MonthAverage})
+	assert.NoError(t, err)
+	assert.Equal(t, "P15DT5H16M48S", s.String())
+
+	s, err = (&Duration{Years: 1}).ScaleFloatWith(0.125, RoundHalfUp, Estimator{})
+	assert.NoError(t, err)
+	assert.Equal(t, "P1M15D", s.String())
+}
+
+func TestScaleFloatRounding(t *testing.T) {
+	t.Parallel()
+
+	third := 1.0 / 3
+	for _, tc := range []struct {
+		d        string
+		f        float64
+		mode     RoundingMode
+		expected string
+	}{
+		{"PT1S", third, RoundHalfUp, "PT0.333333333S"},
+		{"PT1S", third, RoundHalfEven, "PT0.333333333S"},
+		{"PT1S", third, RoundFloor, "PT0.333333333S"},
+		{"PT1S", third, RoundCeil, "PT0.333333334S"},
+		{"-PT1S", third, RoundFloor, "-PT0.333333334S"},
+		{"-PT1S", third, RoundCeil, "-PT0.333333333S"},
+		{"PT2S", third, RoundHalfUp, "PT0.666666667S"},
+		{"PT2S", third, RoundFloor, "PT0.666666666S"},
+		{"PT0.000000001S", 0.5, RoundHalfUp, "PT0.000000001S"},
+		{"PT0.000000001S", 0.5, RoundHalfEven, "PT0S"},
+		{"PT0.000000003S", 0.5, RoundHalfEven, "PT0.000000002S"},
+		{"-PT0.000000001S", 0.5, RoundHalfUp, "-PT0.000000001S"},
+		// 0.1 is slightly more than a tenth as a float64
+		{"PT1S", 0.1, RoundCeil, "PT0.100000001S"},
+		// rounding up may carry into the seconds
+		{"PT0.999999999S", 1.0000000001, RoundCeil, "PT1S"},
+	} {
+		d, err := FromString(tc.d)
+		assert.NoError(t, err)
+
+		s, err := d.ScaleFloat(tc.f, tc.mode)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, s.String(), "%s * %g (%d)", tc.d, tc.f, tc.mode)
+	}
+}
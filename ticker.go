@@ -0,0 +1,180 @@
+package iso8601duration
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotPositive is returned by NewTicker for durations which are not
+// longer than zero
+var ErrNotPositive = errors.New("duration is not positive")
+
+// maxSleep bounds how long a Ticker or Timer sleeps at once, so that it notices
+// when the wall clock is adjusted
+const maxSleep = time.Minute
+
+// Clock is the source of time of a Ticker or Timer. Tests can provide a fake
+// clock to avoid waiting for calendar occurrences.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTimer returns a channel receiving the time once d has
+	// elapsed, along with a function to stop the timer early
+	NewTimer(d time.Duration) (c <-chan time.Time, stop func() bool)
+}
+
+// realClock is the Clock based on package time
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// Ticker delivers the occurrences of a duration on a channel, like
+// time.Ticker does for fixed intervals. The n-th tick is due at the
+// start time plus n times the duration, computed like by Occurrences,
+// so a ticker of P1M started on January 31 ticks on the last day of
+// February and on March 31.
+//
+// Rather than relying on a fixed interval, the ticker sleeps until the
+// next occurrence and checks the clock again when it wakes up, at
+// least once a minute, so it follows adjustments of the wall clock.
+// Like with time.Ticker, ticks are dropped if the receiver falls
+// behind, and only the latest of several missed occurrences is sent
+// when the clock jumps ahead. Durations of a fixed length shorter than a second are
+// stepped like a plain ticker, without the calendar.
+type Ticker struct {
+	// C receives the time of each occurrence
+	C <-chan time.Time
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewTicker returns a ticker delivering the occurrences of d after
+// start, see Ticker. If start lies in the past, the occurrences which
+// have already passed are skipped. It returns ErrMixedSigns if the
+// components of d have different signs and ErrNotPositive if d is not
+// longer than zero.
+func NewTicker(d *Duration, start time.Time) (*Ticker, error) {
+	return NewTickerWithClock(d, start, realClock{})
+}
+
+// NewTickerWithClock works like NewTicker, but takes the time from
+// clock
+func NewTickerWithClock(d *Duration, start time.Time, clock Clock) (*Ticker, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	d = d.canonical()
+	if d == nil || d.Negative || d.IsZero() {
+		return nil, ErrNotPositive
+	}
+
+	c := make(chan time.Time, 1)
+	t := &Ticker{C: c, stop: make(chan struct{})}
+	go t.run(d, start, clock, c)
+	return t, nil
+}
+
+// Stop turns off the ticker and ends its goroutine. Like with
+// time.Ticker, C is not closed.
+func (t *Ticker) Stop() {
+	t.once.Do(func() {
+		close(t.stop)
+	})
+}
+
+// run sends the occurrences until the ticker is stopped or the
+// occurrences overflow
+func (t *Ticker) run(d *Duration, start time.Time, clock Clock, c chan<- time.Time) {
+	at := func(n int) (time.Time, bool) {
+		step, err := d.MulChecked(int64(n))
+		if err != nil {
+			return time.Time{}, false
+		}
+		return step.addToClamped(start), true
+	}
+	if interval, err := d.FixedInterval(); err == nil && interval < time.Second {
+		at = func(n int) (time.Time, bool) {
+			return start.Add(time.Duration(n) * interval), true
+		}
+	}
+
+	// guess the first occurrence after now from the estimate, so that
+	// a start far in the past does not take long to catch up
+	n := 1
+	if elapsed := clock.Now().Sub(start); elapsed > 0 {
+		if guess := int(elapsed/d.ToEstimatedDuration()) - 1; guess > n {
+			n = guess
+		}
+	}
+	for n > 1 {
+		prev, ok := at(n - 1)
+		if !ok || !prev.After(clock.Now()) {
+			break
+		}
+		n--
+	}
+
+	for {
+		next, ok := at(n)
+		if !ok {
+			return
+		}
+		for now := clock.Now(); !next.After(now); now = clock.Now() {
+			if next, ok = at(n + 1); !ok {
+				return
+			}
+			n++
+		}
+
+		if !sleepUntil(next, clock, t.stop) {
+			return
+		}
+
+		// only the latest occurrence is sent after waking up late
+		for {
+			later, ok := at(n + 1)
+			if !ok || later.After(clock.Now()) {
+				break
+			}
+			next = later
+			n++
+		}
+
+		select {
+		case c <- next:
+		default:
+		}
+		n++
+	}
+}
+
+// sleepUntil blocks until clock has reached next and reports whether
+// it did so before stop was closed
+func sleepUntil(next time.Time, clock Clock, stop <-chan struct{}) bool {
+	for {
+		wait := next.Sub(clock.Now())
+		if wait <= 0 {
+			return true
+		}
+		if wait > maxSleep {
+			wait = maxSleep
+		}
+
+		timer, stopTimer := clock.NewTimer(wait)
+		select {
+		case <-timer:
+		case <-stop:
+			stopTimer()
+			return false
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package iso8601duration
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock whose time only moves when it is set
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	deadline time.Time
+	c        chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{deadline: f.now.Add(d), c: make(chan time.Time, 1)}
+	f.timers = append(f.timers, t)
+	return t.c, func() bool { return f.remove(t) }
+}
+
+func (f *fakeClock) remove(t *fakeTimer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, other := range f.timers {
+		if other == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// set moves the clock to now and fires every timer which is due. Like
+// a wall clock, it may move backwards.
+func (f *fakeClock) set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		if now.Before(t.deadline) {
+			pending = append(pending, t)
+		} else {
+			t.c <- now
+		}
+	}
+	f.timers = pending
+}
+
+// waitForTimer blocks until a timer is pending, which means that the
+// ticker is asleep
+func (f *fakeClock) waitForTimer(t *testing.T) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		f.mu.Lock()
+		n := len(f.timers)
+		f.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("ticker did not go to sleep")
+}
+
+// receive returns the next tick or fails after a while
+func receive(t *testing.T, tk *Ticker) time.Time {
+	select {
+	case tick := <-tk.C:
+		return tick
+	case <-time.After(5 * time.Second):
+		t.Fatal("no tick received")
+	}
+	return time.Time{}
+}
+
+// assertNoTick fails if a tick is pending once the ticker is asleep
+func assertNoTick(t *testing.T, clock *fakeClock, tk *Ticker) {
+	clock.waitForTimer(t)
+	select {
+	case tick := <-tk.C:
+		t.Fatalf("unexpected tick at %s", tick)
+	default:
+	}
+}
+
+func TestTickerMonthly(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	tk, err := NewTickerWithClock(MustFromString("P1M"), start, clock)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tk.Stop()
+
+	for _, expected := range []time.Time{
+		time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 30, 9, 0, 0, 0, time.UTC),
+	} {
+		// no tick a second before the occurrence
+		clock.waitForTimer(t)
+		clock.set(expected.Add(-time.Second))
+		assertNoTick(t, clock, tk)
+
+		clock.set(expected)
+		assert.Equal(t, expected, receive(t, tk))
+	}
+}
+
+func TestTickerClockAdjustment(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	tk, err := NewTickerWithClock(MustFromString("P1D"), start, clock)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tk.Stop()
+
+	// the clock is set back while the ticker sleeps
+	clock.waitForTimer(t)
+	clock.set(start.Add(12 * time.Hour))
+	assertNoTick(t, clock, tk)
+	clock.set(start.Add(-12 * time.Hour))
+	assertNoTick(t, clock, tk)
+
+	// the ticker still waits for the next occurrence by the clock
+	clock.set(start.Add(23 * time.Hour))
+	assertNoTick(t, clock, tk)
+	clock.set(start.AddDate(0, 0, 1))
+	assert.Equal(t, start.AddDate(0, 0, 1), receive(t, tk))
+
+	// the clock jumps ahead, only the latest missed occurrence is sent
+	clock.waitForTimer(t)
+	clock.set(start.AddDate(0, 0, 5).Add(time.Hour))
+	assert.Equal(t, start.AddDate(0, 0, 5), receive(t, tk))
+	assertNoTick(t, clock, tk)
+	clock.set(start.AddDate(0, 0, 6))
+	assert.Equal(t, start.AddDate(0, 0, 6), receive(t, tk))
+}
+
+func TestTickerStartInThePast(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2000, time.January, 31, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2023, time.February, 10, 0, 0, 0, 0, time.UTC)
+	clock := newFakeClock(now)
+	tk, err := NewTickerWithClock(MustFromString("P1M"), start, clock)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tk.Stop()
+
+	assertNoTick(t, clock, tk)
+	next := time.Date(2023, time.February, 28, 0, 0, 0, 0, time.UTC)
+	clock.set(next)
+	assert.Equal(t, next, receive(t, tk))
+}
+
+func TestTickerSubSecond(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	tk, err := NewTickerWithClock(MustFromString("PT0.25S"), start, clock)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tk.Stop()
+
+	for i := 1; i <= 4; i++ {
+		clock.waitForTimer(t)
+		expected := start.Add(time.Duration(i) * 250 * time.Millisecond)
+		clock.set(expected)
+		assert.Equal(t, expected, receive(t, tk))
+	}
+
+	// the real clock works the same way
+	tk2, err := NewTicker(MustFromString("PT0.01S"), time.Now())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tk2.Stop()
+	first, second := receive(t, tk2), receive(t, tk2)
+	assert.True(t, second.After(first))
+}
+
+func TestTickerStop(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	tk, err := NewTickerWithClock(MustFromString("PT1H"), start, clock)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	clock.waitForTimer(t)
+	tk.Stop()
+	tk.Stop()
+
+	// stopping removes the pending timer
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		clock.mu.Lock()
+		n := len(clock.timers)
+		clock.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.set(start.Add(2 * time.Hour))
+	select {
+	case tick := <-tk.C:
+		t.Fatalf("unexpected tick at %s", tick)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestNewTickerErrors(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	for _, d := range []*Duration{nil, {}, {Negative: true, Days: 1}, {Days: -1}} {
+		_, err := NewTicker(d, now)
+		assert.ErrorIs(t, err, ErrNotPositive, "%+v", d)
+	}
+	_, err := NewTicker(&Duration{Days: 1, Hours: -1}, now)
+	assert.ErrorIs(t, err, ErrMixedSigns)
+}
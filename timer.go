@@ -0,0 +1,135 @@
+package iso8601duration
+
+import (
+	"sync"
+	"time"
+)
+
+// Timer fires once when a duration has passed, like time.Timer. The
+// target is computed with AddTo, so a timer of P1M started on March 31
+// fires on May 1, as April 31 rolls over.
+//
+// Rather than arming a single timer for the whole duration, the timer
+// sleeps at most a minute at once and checks the clock again when it
+// wakes up. It thus fires at most a minute late by the clock, even if
+// the machine was suspended or the wall clock was adjusted, and never
+// early.
+type Timer struct {
+	// C receives the target time when the timer fires. It is nil for
+	// timers created by AfterFunc.
+	C <-chan time.Time
+
+	c     chan time.Time
+	f     func()
+	clock Clock
+
+	mu     sync.Mutex
+	stop   chan struct{}
+	active bool
+}
+
+// NewTimer returns a timer which sends the target time on its channel
+// once d has passed when starting at from. Zero and negative durations
+// fire right away.
+func NewTimer(d *Duration, from time.Time) *Timer {
+	return NewTimerWithClock(d, from, realClock{})
+}
+
+// NewTimerWithClock works like NewTimer, but takes the time from clock
+func NewTimerWithClock(d *Duration, from time.Time, clock Clock) *Timer {
+	c := make(chan time.Time, 1)
+	t := &Timer{C: c, c: c, clock: clock}
+	t.arm(d.AddTo(from))
+	return t
+}
+
+// After waits for d to pass when starting at from and then sends the
+// target time on the returned channel, like time.After. The timer can
+// not be stopped, see NewTimer.
+func After(d *Duration, from time.Time) <-chan time.Time {
+	return NewTimer(d, from).C
+}
+
+// AfterFunc waits for d to pass when starting at from and then calls f
+// in its own goroutine, like time.AfterFunc. The returned timer can be
+// used to cancel the call with Stop.
+func AfterFunc(d *Duration, from time.Time, f func()) *Timer {
+	return AfterFuncWithClock(d, from, f, realClock{})
+}
+
+// AfterFuncWithClock works like AfterFunc, but takes the time from
+// clock
+func AfterFuncWithClock(d *Duration, from time.Time, f func(), clock Clock) *Timer {
+	t := &Timer{f: f, clock: clock}
+	t.arm(d.AddTo(from))
+	return t
+}
+
+// Stop prevents the timer from firing. It returns true if the call
+// stops the timer, and false if the timer has already fired or been
+// stopped. Like with time.Timer, C is not drained.
+func (t *Timer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.disarm()
+}
+
+// Reset changes the timer to fire once d has passed when starting at
+// from. It returns true if the timer had been active and false if it
+// had fired or been stopped.
+func (t *Timer) Reset(d *Duration, from time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	active := t.disarm()
+	t.armLocked(d.AddTo(from))
+	return active
+}
+
+// disarm stops the pending wait, if any. The mutex has to be held.
+func (t *Timer) disarm() bool {
+	if !t.active {
+		return false
+	}
+	close(t.stop)
+	t.active = false
+	return true
+}
+
+// arm starts waiting for target
+func (t *Timer) arm(target time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.armLocked(target)
+}
+
+// armLocked works like arm, but expects the mutex to be held
+func (t *Timer) armLocked(target time.Time) {
+	stop := make(chan struct{})
+	t.stop = stop
+	t.active = true
+	go t.run(target, stop)
+}
+
+// run fires the timer at target unless stop is closed before
+func (t *Timer) run(target time.Time, stop chan struct{}) {
+	if !sleepUntil(target, t.clock, stop) {
+		return
+	}
+
+	t.mu.Lock()
+	if t.stop != stop || !t.active {
+		t.mu.Unlock()
+		return
+	}
+	t.active = false
+	t.mu.Unlock()
+
+	if t.f != nil {
+		t.f()
+		return
+	}
+	select {
+	case t.c <- target:
+	default:
+	}
+}
@@ -0,0 +1,132 @@
+package iso8601duration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimer(t *testing.T) {
+	t.Parallel()
+
+	// P1M from March 31 rolls over to May 1
+	from := time.Date(2023, time.March, 31, 12, 0, 0, 0, time.UTC)
+	target := time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+	clock := newFakeClock(from)
+	tm := NewTimerWithClock(MustFromString("P1M"), from, clock)
+
+	clock.waitForTimer(t)
+	clock.set(target.Add(-time.Second))
+	clock.waitForTimer(t)
+	select {
+	case tick := <-tm.C:
+		t.Fatalf("unexpected tick at %s", tick)
+	default:
+	}
+
+	// the clock is set back while the timer sleeps
+	clock.set(from.Add(-time.Hour))
+	clock.waitForTimer(t)
+
+	clock.set(target)
+	select {
+	case tick := <-tm.C:
+		assert.Equal(t, target, tick)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer did not fire")
+	}
+	assert.False(t, tm.Stop())
+}
+
+func TestTimerStopReset(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	clock := newFakeClock(from)
+	tm := NewTimerWithClock(MustFromString("P1D"), from, clock)
+
+	clock.waitForTimer(t)
+	assert.True(t, tm.Stop())
+	assert.False(t, tm.Stop())
+	clock.set(from.AddDate(0, 0, 2))
+	select {
+	case tick := <-tm.C:
+		t.Fatalf("unexpected tick at %s", tick)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// a stopped timer can be reset
+	now := clock.Now()
+	assert.False(t, tm.Reset(MustFromString("PT1H"), now))
+	clock.waitForTimer(t)
+
+	// resetting an active timer replaces the target
+	assert.True(t, tm.Reset(MustFromString("PT2H"), now))
+	clock.set(now.Add(time.Hour))
+	clock.waitForTimer(t)
+	select {
+	case tick := <-tm.C:
+		t.Fatalf("unexpected tick at %s", tick)
+	default:
+	}
+	clock.set(now.Add(2 * time.Hour))
+	select {
+	case tick := <-tm.C:
+		assert.Equal(t, now.Add(2*time.Hour), tick)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer did not fire")
+	}
+}
+
+func TestAfterFunc(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	clock := newFakeClock(from)
+	called := make(chan struct{})
+	tm := AfterFuncWithClock(MustFromString("P1W"), from, func() { close(called) }, clock)
+	assert.Nil(t, tm.C)
+
+	clock.waitForTimer(t)
+	clock.set(from.AddDate(0, 0, 7))
+	select {
+	case <-called:
+	case <-time.After(5 * time.Second):
+		t.Fatal("function was not called")
+	}
+	assert.False(t, tm.Stop())
+
+	// a stopped timer does not call the function
+	tm = AfterFuncWithClock(MustFromString("P1W"), from, func() { t.Error("unexpected call") }, clock)
+	assert.True(t, tm.Stop())
+	clock.set(from.AddDate(0, 0, 14))
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	select {
+	case tick := <-After(MustFromString("PT0.01S"), now):
+		assert.True(t, now.Add(10*time.Millisecond).Equal(tick), tick)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer did not fire")
+	}
+
+	// durations in the past fire right away
+	select {
+	case <-After(MustFromString("-P1D"), time.Now()):
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer did not fire")
+	}
+
+	called := make(chan struct{})
+	AfterFunc(MustFromString("PT0S"), time.Now(), func() { close(called) })
+	select {
+	case <-called:
+	case <-time.After(5 * time.Second):
+		t.Fatal("function was not called")
+	}
+}